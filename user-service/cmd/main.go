@@ -5,12 +5,23 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/Nezent/microservice-template/user-service/config"
 	"github.com/Nezent/microservice-template/user-service/internal/application/service"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/cache"
 	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/hashing"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/idempotency"
 	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/mailer"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/maintenance"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/oauth"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/otp"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/redis"
 	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/repository"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/throttle"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/token"
 	"github.com/Nezent/microservice-template/user-service/internal/interface/handler"
 	"github.com/Nezent/microservice-template/user-service/internal/interface/routes"
 	"github.com/Nezent/microservice-template/user-service/pkg/router"
@@ -19,7 +30,15 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	app := fx.New(
+		fx.StartTimeout(database.StartTimeout),
 		config.Module,
 		router.Module,
 		routes.Module,
@@ -27,8 +46,19 @@ func main() {
 		handler.Module,
 		service.Module,
 		repository.Module,
+		hashing.Module,
+		token.Module,
+		otp.Module,
+		oauth.Module,
+		redis.Module,
+		cache.Module,
+		mailer.Module,
+		idempotency.Module,
+		maintenance.Module,
+		throttle.Module,
 		logger.Module,
 		fx.Invoke(func(
+			cfg *config.Config,
 			router *chi.Mux,
 			routes *routes.APIV1Routes,
 			lc fx.Lifecycle,
@@ -37,9 +67,9 @@ func main() {
 				OnStart: func(_ context.Context) error {
 					// Register routes
 					routes.Register()
-					log.Printf("Server started on port %v", 8080)
+					log.Printf("Server started on port %v", cfg.App.Port)
 					go func() {
-						if err := http.ListenAndServe(fmt.Sprintf(":%d", 8080), router); err != nil {
+						if err := http.ListenAndServe(fmt.Sprintf(":%d", cfg.App.Port), router); err != nil {
 							log.Fatalf("failed to start server: %v", err)
 						}
 					}()
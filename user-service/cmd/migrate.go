@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = "usage: user-service migrate up|down|status|redo|check"
+
+// runMigrate runs a migrate subcommand against the configured database.
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if args[0] == "check" {
+		if err := database.CheckMigrations(ctx, cfg); err != nil {
+			return err
+		}
+		fmt.Println("migrations apply, roll back and re-apply cleanly")
+		return nil
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := m.Up(ctx)
+		printResults(results)
+		return err
+	case "down":
+		result, err := m.Down(ctx)
+		if result != nil {
+			printResults([]*goose.MigrationResult{result})
+		}
+		return err
+	case "redo":
+		results, err := m.Redo(ctx)
+		printResults(results)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.State == goose.StateApplied {
+				appliedAt = s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", appliedAt, s.Source.Path)
+		}
+		return w.Flush()
+	}
+	return errors.New(migrateUsage)
+}
+
+func printResults(results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		fmt.Println("no migrations to run")
+	}
+	for _, r := range results {
+		fmt.Printf("%-4s %s (%s)\n", r.Direction, r.Source.Path, r.Duration.Round(time.Millisecond))
+	}
+}
@@ -1,8 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,12 +19,47 @@ import (
 
 // Root Config struct
 type Config struct {
-	App       AppConfig      `mapstructure:"app"`
-	Database  DatabaseConfig `mapstructure:"database"`
-	Redis     RedisConfig    `mapstructure:"redis"`
-	Log       LogConfig      `mapstructure:"log"`
-	AdminAuth AuthConfig     `mapstructure:"admin_auth"`
-	Auth      AuthConfig     `mapstructure:"auth"`
+	App         AppConfig         `mapstructure:"app"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Throttle    ThrottleConfig    `mapstructure:"throttle"`
+	Mail        MailConfig        `mapstructure:"mail"`
+	Log         LogConfig         `mapstructure:"log"`
+	Hashing     HashingConfig     `mapstructure:"hashing"`
+	AdminAuth   AuthConfig        `mapstructure:"admin_auth"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+}
+
+// Validate checks every section, so that a bad value fails startup, or is
+// rejected on reload, instead of surfacing on first use. All problems are
+// reported at once.
+func (c *Config) Validate() error {
+	sections := []struct {
+		name     string
+		validate func() error
+	}{
+		{"app", c.App.Validate},
+		{"database", c.Database.Validate},
+		{"redis", c.Redis.Validate},
+		{"cache", c.Cache.Validate},
+		{"idempotency", c.Idempotency.Validate},
+		{"throttle", c.Throttle.Validate},
+		{"mail", c.Mail.Validate},
+		{"log", c.Log.Validate},
+		{"hashing", c.Hashing.Validate},
+		{"admin_auth", c.AdminAuth.ValidateAdmin},
+		{"auth", c.Auth.Validate},
+	}
+
+	var errs []error
+	for _, s := range sections {
+		if err := s.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // -------------------- App --------------------
@@ -34,46 +77,180 @@ type AppConfig struct {
 	Host        string            `mapstructure:"host"`
 	Port        int               `mapstructure:"port"`
 	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+
+	// TrustedProxies are addresses or CIDR ranges of reverse proxies whose
+	// X-Real-IP header is taken as the client address.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
+func (a *AppConfig) Validate() error {
+	if a.Name == "" || a.URL == "" {
+		return fmt.Errorf("app name and url must be set")
+	}
+	if a.Env == "" || strings.ContainsAny(a.Env, `/\`) {
+		return fmt.Errorf("invalid app env: %q", a.Env)
+	}
+	if _, err := time.LoadLocation(a.Timezone); err != nil {
+		return fmt.Errorf("invalid app timezone: %s", a.Timezone)
+	}
+	if a.Port <= 0 || a.Port > 65535 {
+		return fmt.Errorf("invalid app port: %d", a.Port)
+	}
+	if _, err := a.TrustedProxyPrefixes(); err != nil {
+		return err
+	}
+	return a.Maintenance.Validate()
+}
+
+// TrustedProxyPrefixes parses TrustedProxies.
+func (a *AppConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	return parsePrefixes(a.TrustedProxies, "trusted proxy")
+}
+
+// MaintenanceConfig controls maintenance mode. It is on while Enabled is set
+// or while the driver's flag is: a file at File, or the maintenance key in
+// Redis, which reaches every instance. The flag's content replaces Message.
 type MaintenanceConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Message string `mapstructure:"message"`
-	Driver  string `mapstructure:"driver"`
+	Enabled    bool          `mapstructure:"enabled"`
+	Message    string        `mapstructure:"message"`
+	Driver     string        `mapstructure:"driver"`
+	File       string        `mapstructure:"file"`
+	RetryAfter time.Duration `mapstructure:"retry_after"`
+	// AllowedIPs are addresses or CIDR ranges that are still served, so that
+	// admins can check the service before it is reopened.
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+}
+
+func (m *MaintenanceConfig) Validate() error {
+	if !slices.Contains([]string{"file", "redis"}, m.Driver) {
+		return fmt.Errorf("invalid maintenance driver: %s", m.Driver)
+	}
+	if m.Driver == "file" && m.File == "" {
+		return fmt.Errorf("maintenance file must be set for the file driver")
+	}
+	if m.RetryAfter < 0 {
+		return fmt.Errorf("maintenance retry_after must not be negative")
+	}
+	if _, err := m.AllowedPrefixes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AllowedPrefixes parses AllowedIPs.
+func (m *MaintenanceConfig) AllowedPrefixes() ([]netip.Prefix, error) {
+	return parsePrefixes(m.AllowedIPs, "maintenance allowed ip")
+}
+
+// parsePrefixes parses addresses and CIDR ranges. Single addresses become
+// one-address prefixes.
+func parsePrefixes(values []string, name string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, s := range values {
+		if addr, err := netip.ParseAddr(s); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", name, s)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
 }
 
 // -------------------- Database --------------------
 
+// DatabaseConfig selects the database connection. Only PostgreSQL is
+// supported: the migrations and repositories rely on features such as jsonb
+// and RETURNING.
 type DatabaseConfig struct {
 	Default  string           `mapstructure:"default"`
-	MySQL    DBInstanceConfig `mapstructure:"mysql"`
 	Postgres DBInstanceConfig `mapstructure:"postgres"`
-	TiDB     DBInstanceConfig `mapstructure:"tidb"`
+	// QueryTimeout bounds every repository query on top of the request's
+	// own deadline.
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// MigrateOnStartup applies pending migrations before the server starts.
+	MigrateOnStartup bool `mapstructure:"migrate_on_startup"`
+	// ReplicaCheckInterval is how often replicas are pinged. Reads go to the
+	// primary while a replica is unreachable.
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
 }
 
 func (d *DatabaseConfig) BuildDsn() string {
 	conn := d.Driver()
+	return conn.BuildDsn()
+}
 
-	switch conn.Driver {
-	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			conn.User, conn.Password, conn.Host, conn.Port, conn.Name)
+// Replicas returns the connection config of each read replica of the
+// selected instance.
+func (d *DatabaseConfig) Replicas() []DBInstanceConfig {
+	conn := d.Driver()
+	replicas := make([]DBInstanceConfig, len(conn.Replicas))
+	for i, r := range conn.Replicas {
+		replicas[i] = conn.replica(r)
+	}
+	return replicas
+}
 
+func (conn *DBInstanceConfig) BuildDsn() string {
+	switch conn.Driver {
 	case "pgsql", "postgres", "postgresql":
-		return fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable",
-			conn.Host, conn.Port, conn.User, conn.Name, conn.Password)
+		sslMode := conn.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(conn.User, conn.Password),
+			Host:     net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port)),
+			Path:     "/" + conn.Name,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return dsn.String()
 	}
 	return ""
 }
 
+// Validate checks the connection selected by Default, so that a typo fails
+// startup instead of the first query.
+func (d *DatabaseConfig) Validate() error {
+	if !slices.Contains([]string{"postgres", "postgresql"}, d.Default) {
+		return fmt.Errorf("unsupported database default: %q, use postgres", d.Default)
+	}
+	conn := d.Driver()
+	if !slices.Contains([]string{"pgsql", "postgres", "postgresql"}, conn.Driver) {
+		return fmt.Errorf("unsupported database driver for %s: %q, use postgres", d.Default, conn.Driver)
+	}
+	if conn.Host == "" || conn.Port <= 0 || conn.User == "" || conn.Name == "" {
+		return fmt.Errorf("database %s requires host, port, user and name", d.Default)
+	}
+	if conn.Password == "" {
+		return fmt.Errorf("database password must be set with APP_DATABASE_POSTGRES_PASSWORD or APP_DATABASE_POSTGRES_PASSWORD_FILE")
+	}
+	if err := conn.Pool.Validate(); err != nil {
+		return fmt.Errorf("database %s: %w", d.Default, err)
+	}
+	for i, r := range conn.Replicas {
+		if r.Host == "" {
+			return fmt.Errorf("database %s replica %d requires a host", d.Default, i)
+		}
+	}
+	if len(conn.Replicas) > 0 && d.ReplicaCheckInterval <= 0 {
+		return fmt.Errorf("database replica_check_interval must be positive")
+	}
+	if d.QueryTimeout <= 0 {
+		return fmt.Errorf("database query_timeout must be positive")
+	}
+	return nil
+}
+
 func (d *DatabaseConfig) Driver() DBInstanceConfig {
 	switch d.Default {
-	case "mysql":
-		return d.MySQL
 	case "postgres", "postgresql":
 		return d.Postgres
-	case "tidb":
-		return d.TiDB
 	default:
 		return DBInstanceConfig{
 			Driver: d.Default,
@@ -88,16 +265,54 @@ type DBInstanceConfig struct {
 	User          string     `mapstructure:"user"`
 	Password      string     `mapstructure:"password"`
 	Name          string     `mapstructure:"name"`
+	SSLMode       string     `mapstructure:"sslmode"`
 	MigrationPath string     `mapstructure:"migration_path"`
 	Pool          PoolConfig `mapstructure:"pool"`
+	// Replicas are read-only copies of the instance that serve reads.
+	Replicas []ReplicaConfig `mapstructure:"replicas"`
+}
+
+// ReplicaConfig is a read replica. Empty fields default to the primary's.
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
 }
 
+// replica returns the instance config of r, filled in from conn.
+func (conn *DBInstanceConfig) replica(r ReplicaConfig) DBInstanceConfig {
+	replica := *conn
+	replica.Host = r.Host
+	replica.Replicas = nil
+	if r.Port != 0 {
+		replica.Port = r.Port
+	}
+	if r.User != "" {
+		replica.User = r.User
+		replica.Password = r.Password
+	}
+	return replica
+}
+
+// PoolConfig sizes the connection pool. Zero values keep the database/sql
+// defaults.
 type PoolConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_connections"`
 	MaxOpenConns    int           `mapstructure:"max_open_connections"`
 	ConnMaxLifetime time.Duration `mapstructure:"max_connection_lifetime"`
 }
 
+func (p *PoolConfig) Validate() error {
+	if p.MaxIdleConns < 0 || p.MaxOpenConns < 0 || p.ConnMaxLifetime < 0 {
+		return fmt.Errorf("pool settings must not be negative")
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("pool max_idle_connections must not exceed max_open_connections")
+	}
+	return nil
+}
+
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
 	Username string `mapstructure:"username"`
@@ -106,6 +321,127 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+func (r *RedisConfig) Validate() error {
+	if r.Addr == "" && r.Default == "" {
+		return fmt.Errorf("redis addr must be set")
+	}
+	if r.DB < 0 {
+		return fmt.Errorf("redis db must not be negative")
+	}
+	return nil
+}
+
+// -------------------- Cache --------------------
+
+// CacheConfig controls the cache in front of user lookups. The memory driver
+// keeps up to size users per instance; redis shares them between instances.
+type CacheConfig struct {
+	Driver string        `mapstructure:"driver"`
+	TTL    time.Duration `mapstructure:"ttl"`
+	Size   int           `mapstructure:"size"`
+}
+
+func (c *CacheConfig) Validate() error {
+	if !slices.Contains([]string{"none", "memory", "redis"}, c.Driver) {
+		return fmt.Errorf("invalid cache driver: %s", c.Driver)
+	}
+	if c.Driver == "none" {
+		return nil
+	}
+	if c.TTL <= 0 {
+		return fmt.Errorf("cache ttl must be positive")
+	}
+	if c.Driver == "memory" && c.Size <= 0 {
+		return fmt.Errorf("cache size must be positive for the memory driver")
+	}
+	return nil
+}
+
+// -------------------- Idempotency --------------------
+
+type IdempotencyConfig struct {
+	Driver  string        `mapstructure:"driver"`
+	TTL     time.Duration `mapstructure:"ttl"`
+	LockTTL time.Duration `mapstructure:"lock_ttl"`
+	Secret  string        `mapstructure:"secret"`
+	// SweepInterval is how often the postgres driver deletes expired keys.
+	// Redis expires them on its own.
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+func (i *IdempotencyConfig) Validate() error {
+	if !slices.Contains([]string{"postgres", "redis"}, i.Driver) {
+		return fmt.Errorf("invalid idempotency driver: %s", i.Driver)
+	}
+	if i.TTL <= 0 || i.LockTTL <= 0 {
+		return fmt.Errorf("idempotency ttl and lock_ttl must be positive")
+	}
+	if i.Secret == "" {
+		return fmt.Errorf("idempotency secret must be set")
+	}
+	if i.Driver == "postgres" && i.SweepInterval <= 0 {
+		return fmt.Errorf("idempotency sweep_interval must be positive")
+	}
+	return nil
+}
+
+// -------------------- Throttle --------------------
+
+// ThrottleConfig controls brute-force protection on sign-in. Every failure
+// beyond the free attempts locks the key out for twice as long as the last
+// time, starting at base_lockout and capped at max_lockout.
+type ThrottleConfig struct {
+	Driver          string        `mapstructure:"driver"`
+	Window          time.Duration `mapstructure:"window"`
+	IPAttempts      int           `mapstructure:"ip_attempts"`
+	AccountAttempts int           `mapstructure:"account_attempts"`
+	BaseLockout     time.Duration `mapstructure:"base_lockout"`
+	MaxLockout      time.Duration `mapstructure:"max_lockout"`
+}
+
+func (t *ThrottleConfig) Validate() error {
+	if !slices.Contains([]string{"memory", "redis"}, t.Driver) {
+		return fmt.Errorf("invalid throttle driver: %s", t.Driver)
+	}
+	if t.Window <= 0 || t.BaseLockout <= 0 || t.MaxLockout < t.BaseLockout {
+		return fmt.Errorf("throttle window and base_lockout must be positive and max_lockout at least base_lockout")
+	}
+	if t.IPAttempts <= 0 || t.AccountAttempts <= 0 {
+		return fmt.Errorf("throttle ip_attempts and account_attempts must be positive")
+	}
+	return nil
+}
+
+// -------------------- Mail --------------------
+
+type MailConfig struct {
+	Driver      string         `mapstructure:"driver"`
+	FromAddress string         `mapstructure:"from_address"`
+	FromName    string         `mapstructure:"from_name"`
+	LogPath     string         `mapstructure:"log_path"`
+	SMTP        SMTPMailConfig `mapstructure:"smtp"`
+}
+
+type SMTPMailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+func (m *MailConfig) Validate() error {
+	if !slices.Contains([]string{"log", "smtp"}, m.Driver) {
+		return fmt.Errorf("invalid mail driver: %s", m.Driver)
+	}
+	if m.FromAddress == "" {
+		return fmt.Errorf("mail from_address must be set")
+	}
+	if m.Driver == "smtp" && (m.SMTP.Host == "" || m.SMTP.Port == 0) {
+		return fmt.Errorf("smtp host and port must be set")
+	}
+	return nil
+}
+
 type LogConfig struct {
 	Level             string            `mapstructure:"level"`
 	Format            string            `mapstructure:"format"`
@@ -155,12 +491,78 @@ func (l *LogConfig) Validate() error {
 	return nil
 }
 
+// -------------------- Hashing --------------------
+
+type HashingConfig struct {
+	Driver string           `mapstructure:"driver"`
+	Bcrypt BcryptHashConfig `mapstructure:"bcrypt"`
+	Argon  ArgonHashConfig  `mapstructure:"argon"`
+}
+
+type BcryptHashConfig struct {
+	Rounds int `mapstructure:"rounds"`
+}
+
+type ArgonHashConfig struct {
+	Memory  uint32 `mapstructure:"memory"`
+	Threads uint8  `mapstructure:"threads"`
+	Time    uint32 `mapstructure:"time"`
+}
+
+func (h *HashingConfig) Validate() error {
+	if !slices.Contains([]string{"argon2id", "bcrypt"}, h.Driver) {
+		return fmt.Errorf("invalid hashing driver: %s", h.Driver)
+	}
+	if h.Bcrypt.Rounds != 0 && (h.Bcrypt.Rounds < 10 || h.Bcrypt.Rounds > 31) {
+		return fmt.Errorf("bcrypt rounds must be between 10 and 31: %d", h.Bcrypt.Rounds)
+	}
+	if h.Argon.Memory != 0 && h.Argon.Memory < 8*uint32(h.Argon.Threads) {
+		return fmt.Errorf("argon memory must be at least 8 KiB per thread")
+	}
+	return nil
+}
+
 // -------------------- Auth --------------------
 
 type AuthConfig struct {
-	JWT   JWTConfig   `mapstructure:"jwt"`
-	OTP   OTPConfig   `mapstructure:"otp"`
-	OAuth OAuthConfig `mapstructure:"oauth"`
+	JWT               JWTConfig               `mapstructure:"jwt"`
+	OTP               OTPConfig               `mapstructure:"otp"`
+	OAuth             OAuthConfig             `mapstructure:"oauth"`
+	EmailVerification EmailVerificationConfig `mapstructure:"email_verification"`
+	PasswordReset     PasswordResetConfig     `mapstructure:"password_reset"`
+}
+
+func (a *AuthConfig) Validate() error {
+	if err := a.JWT.Validate(); err != nil {
+		return err
+	}
+	if err := a.OTP.Validate(); err != nil {
+		return err
+	}
+	if err := a.EmailVerification.Validate(); err != nil {
+		return err
+	}
+	if a.PasswordReset.URL == "" {
+		return fmt.Errorf("password reset url must be set")
+	}
+	for name, p := range a.OAuth.Providers() {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAdmin checks the admin flavour, which only uses the JWT settings
+// and the lifetime of the second-step challenge.
+func (a *AuthConfig) ValidateAdmin() error {
+	if err := a.JWT.Validate(); err != nil {
+		return err
+	}
+	if a.OTP.ExpiresIn <= 0 {
+		return fmt.Errorf("otp expires_in must be positive")
+	}
+	return nil
 }
 
 type JWTConfig struct {
@@ -171,12 +573,57 @@ type JWTConfig struct {
 	RefreshTokenExpiresIn time.Duration `mapstructure:"refresh_token_expires_in"`
 }
 
+func (j *JWTConfig) Validate() error {
+	if !slices.Contains([]string{"RS256", "EdDSA"}, j.Algorithm) {
+		return fmt.Errorf("invalid jwt algorithm: %s", j.Algorithm)
+	}
+	if j.PublicKey == "" || j.PrivateKey == "" {
+		return fmt.Errorf("jwt public_key and private_key must be set")
+	}
+	if j.AccessTokenExpiresIn <= 0 || j.RefreshTokenExpiresIn <= 0 {
+		return fmt.Errorf("jwt token lifetimes must be positive")
+	}
+	return nil
+}
+
 type OTPConfig struct {
 	ExpiresIn time.Duration `mapstructure:"expires_in"`
 	Secret    string        `mapstructure:"secret"`
 	Length    int           `mapstructure:"length"`
 }
 
+func (o *OTPConfig) Validate() error {
+	if o.Secret == "" {
+		return fmt.Errorf("otp secret must be set")
+	}
+	if o.Length != 6 && o.Length != 8 {
+		return fmt.Errorf("otp length must be 6 or 8: %d", o.Length)
+	}
+	if o.ExpiresIn <= 0 {
+		return fmt.Errorf("otp expires_in must be positive")
+	}
+	return nil
+}
+
+type EmailVerificationConfig struct {
+	ExpiresIn time.Duration `mapstructure:"expires_in"`
+	URL       string        `mapstructure:"url"`
+}
+
+func (e *EmailVerificationConfig) Validate() error {
+	if e.ExpiresIn <= 0 {
+		return fmt.Errorf("email verification expires_in must be positive")
+	}
+	if e.URL == "" {
+		return fmt.Errorf("email verification url must be set")
+	}
+	return nil
+}
+
+type PasswordResetConfig struct {
+	URL string `mapstructure:"url"`
+}
+
 type OAuthConfig struct {
 	FrontendURL string        `mapstructure:"frontend_url"`
 	Google      OAuthProvider `mapstructure:"google"`
@@ -184,31 +631,134 @@ type OAuthConfig struct {
 	Apple       OAuthProvider `mapstructure:"apple"`
 }
 
+// Providers returns the configured providers keyed by name. A provider is
+// disabled when its client ID is empty.
+func (o *OAuthConfig) Providers() map[string]OAuthProvider {
+	providers := map[string]OAuthProvider{}
+	for name, p := range map[string]OAuthProvider{
+		"google":   o.Google,
+		"facebook": o.Facebook,
+		"apple":    o.Apple,
+	} {
+		if p.ClientID != "" {
+			providers[name] = p
+		}
+	}
+	return providers
+}
+
 type OAuthProvider struct {
-	ClientID     string `mapstructure:"client_id"`
-	ClientSecret string `mapstructure:"client_secret"`
-	RedirectURL  string `mapstructure:"redirect_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	AuthURL      string   `mapstructure:"auth_url"`
+	TokenURL     string   `mapstructure:"token_url"`
+	UserInfoURL  string   `mapstructure:"userinfo_url"`
+	Issuer       string   `mapstructure:"issuer"`
+	JWKSURL      string   `mapstructure:"jwks_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+func (p *OAuthProvider) Validate() error {
+	if p.RedirectURL == "" || p.AuthURL == "" || p.TokenURL == "" {
+		return fmt.Errorf("oauth provider requires redirect_url, auth_url and token_url")
+	}
+	// Without a userinfo endpoint the profile comes from the id_token, which
+	// must be verified against the provider's keys.
+	if p.UserInfoURL == "" && (p.Issuer == "" || p.JWKSURL == "") {
+		return fmt.Errorf("oauth provider without userinfo_url requires issuer and jwks_url")
+	}
+	return nil
 }
 
 // -------------------- Loading --------------------
 
+// DefaultPath is the base config file, relative to the working directory.
+const DefaultPath = "config/config.yaml"
+
+// EnvPrefix prefixes the environment variables that override config keys:
+// database.postgres.password is read from APP_DATABASE_POSTGRES_PASSWORD.
+const EnvPrefix = "APP"
+
+// LoadConfig reads the configuration in layers, each overriding the last:
+//
+//  1. the base file at path
+//  2. the file for app.env next to it, e.g. config.production.yaml, if any
+//  3. APP_ environment variables
+//  4. secret files named by APP_..._FILE variables
+//
+// The result is validated as a whole.
 func LoadConfig(path string) (*Config, error) {
-	viper.SetConfigFile(path)
-	viper.SetConfigType("yaml")
+	cfg, _, err := load(path)
+	return cfg, err
+}
+
+// load implements LoadConfig and also returns the files that were read.
+func load(path string) (*Config, []string, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	files := []string{path}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// app.env may itself come from APP_APP_ENV
+	if env := v.GetString("app.env"); env != "" {
+		ext := filepath.Ext(path)
+		envPath := strings.TrimSuffix(path, ext) + "." + env + ext
+		if _, err := os.Stat(envPath); err == nil {
+			v.SetConfigFile(envPath)
+			if err := v.MergeInConfig(); err != nil {
+				return nil, nil, fmt.Errorf("error reading config file: %w", err)
+			}
+			files = append(files, envPath)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("error reading config file: %w", err)
+		}
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+	secrets, err := applySecretFiles(v)
+	if err != nil {
+		return nil, nil, err
 	}
+	files = append(files, secrets...)
 
 	var cfg Config
-
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("error unmarshaling config: %w", err)
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return &cfg, files, nil
+}
 
-	return &cfg, nil
+// applySecretFiles sets every key whose APP_..._FILE variable names a file,
+// such as a mounted Docker or Kubernetes secret, to the file's content. It
+// returns the files it read.
+func applySecretFiles(v *viper.Viper) ([]string, error) {
+	var files []string
+	for _, key := range v.AllKeys() {
+		name := EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + "_FILE"
+		path := os.Getenv(name)
+		if path == "" {
+			continue
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+		v.Set(key, strings.TrimRight(string(raw), "\r\n"))
+		files = append(files, path)
+	}
+	return files, nil
 }
 
 func NewConfig() (*Config, error) {
-	return LoadConfig("config/config.yaml")
+	return LoadConfig(DefaultPath)
 }
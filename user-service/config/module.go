@@ -1,5 +1,38 @@
 package config
 
-import "go.uber.org/fx"
+import (
+	"context"
 
-var Module = fx.Module("config", fx.Provide(NewConfig))
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"config",
+	fx.Provide(
+		ProvideManager,
+		ProvideConfig,
+	),
+)
+
+// ProvideManager loads the configuration and watches its files while the
+// application runs.
+func ProvideManager(lc fx.Lifecycle) (*Manager, error) {
+	m, err := NewManager(DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return m.Watch()
+		},
+		OnStop: func(context.Context) error {
+			return m.Close()
+		},
+	})
+	return m, nil
+}
+
+// ProvideConfig provides the configuration as loaded at startup.
+func ProvideConfig(m *Manager) *Config {
+	return m.Current()
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay collapses the burst of events that an editor or a config map
+// update produces into a single reload.
+const reloadDelay = 200 * time.Millisecond
+
+// Manager holds the current configuration and reloads it when one of its
+// files changes. The *Config provided at startup never changes; components
+// that can apply new values register with OnReload.
+type Manager struct {
+	path     string
+	watcher  *fsnotify.Watcher
+	mu       sync.RWMutex
+	current  *Config
+	files    []string
+	onReload []func(*Config)
+	onError  []func(error)
+}
+
+func NewManager(path string) (*Manager, error) {
+	cfg, files, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		path:    path,
+		current: cfg,
+		files:   files,
+	}, nil
+}
+
+// Current returns the configuration as of the last successful reload.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// OnReload registers fn to be called with every new configuration.
+func (m *Manager) OnReload(fn func(*Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onReload = append(m.onReload, fn)
+}
+
+// OnReloadError registers fn to be called when a reload is rejected.
+func (m *Manager) OnReloadError(fn func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = append(m.onError, fn)
+}
+
+// Reload reads the configuration again and notifies the OnReload callbacks
+// if it changed. An invalid configuration is rejected and the current one
+// kept.
+func (m *Manager) Reload() error {
+	cfg, files, err := load(m.path)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	changed := !reflect.DeepEqual(cfg, m.current)
+	if changed {
+		m.current = cfg
+	}
+	m.files = files
+	callbacks := slices.Clone(m.onReload)
+	m.mu.Unlock()
+
+	if changed {
+		for _, fn := range callbacks {
+			fn(cfg)
+		}
+	}
+	return nil
+}
+
+// Watch reloads the configuration whenever its files change, until Close.
+// Directories are watched rather than files because editors and config map
+// updates replace files instead of writing to them.
+func (m *Manager) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	m.watcher = watcher
+	if err := m.watchDirs(); err != nil {
+		watcher.Close()
+		return err
+	}
+	go m.run()
+	return nil
+}
+
+// Close stops watching.
+func (m *Manager) Close() error {
+	if m.watcher == nil {
+		return nil
+	}
+	return m.watcher.Close()
+}
+
+func (m *Manager) run() {
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case _, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			m.reportError(err)
+		case <-timer.C:
+			if err := m.Reload(); err != nil {
+				m.reportError(err)
+			}
+			// The environment file or a secret may have appeared elsewhere
+			if err := m.watchDirs(); err != nil {
+				m.reportError(err)
+			}
+		}
+	}
+}
+
+// watchDirs adds the directories of the config files to the watcher.
+// Directories that are already watched are left as they are.
+func (m *Manager) watchDirs() error {
+	dirs := []string{filepath.Dir(m.path)}
+	m.mu.RLock()
+	for _, f := range m.files {
+		dirs = append(dirs, filepath.Dir(f))
+	}
+	m.mu.RUnlock()
+
+	for _, dir := range dirs {
+		if slices.Contains(m.watcher.WatchList(), dir) {
+			continue
+		}
+		if err := m.watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) reportError(err error) {
+	m.mu.RLock()
+	callbacks := slices.Clone(m.onError)
+	m.mu.RUnlock()
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
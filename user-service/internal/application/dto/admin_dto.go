@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminTokenResponse represents an admin access token. Admin sessions cannot
+// be refreshed; admins sign in again once the token expires.
+type AdminTokenResponse struct {
+	AccessToken string   `json:"access_token"`
+	TokenType   string   `json:"token_type"`
+	ExpiresIn   int64    `json:"expires_in"`
+	Permissions []string `json:"permissions"`
+}
+
+// AdminUserDetail represents a user as seen through the admin API.
+type AdminUserDetail struct {
+	UserDetail
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	Roles      []string   `json:"roles"`
+}
+
+// AdminUserListResponse represents a page of users in the admin API.
+type AdminUserListResponse struct {
+	Users []AdminUserDetail `json:"users"`
+}
+
+// SetUserRolesRequest represents the payload for replacing a user's roles.
+// An empty list removes every role.
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"max=20,dive,required,max=50"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// RoleDetail represents a role and the permissions it grants.
+type RoleDetail struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleListResponse represents the list of roles.
+type RoleListResponse struct {
+	Roles []RoleDetail `json:"roles"`
+}
+
+// ListAuditLogRequest represents the query parameters for the audit log.
+type ListAuditLogRequest struct {
+	UserID  *uuid.UUID
+	ActorID *uuid.UUID
+	From    *time.Time
+	To      *time.Time
+	Cursor  string
+	Limit   int
+}
+
+// AuditEntryDetail represents a single audit log entry.
+type AuditEntryDetail struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	ActorID   string         `json:"actor_id"`
+	Action    string         `json:"action"`
+	IP        string         `json:"ip,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// AuditLogResponse represents a page of the audit log.
+type AuditLogResponse struct {
+	Entries []AuditEntryDetail `json:"entries"`
+}
@@ -0,0 +1,95 @@
+package dto
+
+import "time"
+
+// LoginRequest represents the payload for signing in with email and password.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// TwoFactorLoginRequest represents the second login step for users with
+// two-factor authentication enabled.
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// LoginResponse represents the result of the first login step. Either the
+// tokens are issued directly or a second factor is required.
+type LoginResponse struct {
+	*TokenResponse
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// Linked is set by the OAuth callback when it linked a provider to the
+	// signed-in user instead of signing in.
+	Linked bool `json:"-"`
+}
+
+// RefreshTokenRequest represents the payload for rotating a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// ClientInfo describes the device a request came from. It is used for
+// brute-force protection and recorded on sessions and in the audit log.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// TokenResponse represents the tokens issued after a successful login or refresh.
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+
+// OAuthStartResponse represents a pending OAuth authorization request.
+type OAuthStartResponse struct {
+	URL       string `json:"url"`
+	State     string `json:"state"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// JWK represents a single public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKSResponse represents a JSON Web Key Set.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// SessionDetail represents a signed-in device of the user.
+type SessionDetail struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SessionListResponse represents the active sessions of the user.
+type SessionListResponse struct {
+	Sessions []SessionDetail `json:"sessions"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// BalanceResponse represents a user's current balance in minor units.
+type BalanceResponse struct {
+	Balance   int64     `json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LedgerOperationRequest represents a credit or debit of a user's balance.
+// Amount is in minor units. Reference is optional and must be unique, so
+// retrying an operation with the same reference is rejected.
+type LedgerOperationRequest struct {
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Reference   string `json:"reference" binding:"omitempty,max=100"`
+	Description string `json:"description" binding:"omitempty,max=255"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// LedgerEntryDetail represents one movement on a user's balance.
+type LedgerEntryDetail struct {
+	ID            string    `json:"id"`
+	TransactionID string    `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Amount        int64     `json:"amount"`
+	BalanceAfter  int64     `json:"balance_after"`
+	Reference     string    `json:"reference,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ListTransactionsRequest represents the query parameters for the
+// transaction history.
+type ListTransactionsRequest struct {
+	Cursor string
+	Limit  int
+}
+
+// TransactionHistoryResponse represents a page of a user's transactions.
+type TransactionHistoryResponse struct {
+	Transactions []LedgerEntryDetail `json:"transactions"`
+}
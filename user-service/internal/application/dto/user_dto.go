@@ -1,10 +1,14 @@
 package dto
 
+import "time"
+
 // CreateUserRequest represents the payload for creating a new user.
 type CreateUserRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required,min=6,max=128"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
 }
 
 // CreateUserResponse represents the response after creating a new user.
@@ -12,14 +16,103 @@ type CreateUserResponse struct {
 	ID string `json:"id"`
 }
 
+// UpdateProfileRequest represents the payload for updating a user's profile.
+type UpdateProfileRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// ChangeEmailRequest represents the payload for changing a user's email. The
+// current password is required to confirm the change.
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// VerifyEmailRequest represents the payload for confirming an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ChangePasswordRequest represents the payload for changing the password of
+// the authenticated user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6,max=128"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// ForgotPasswordRequest represents the payload for requesting a reset link.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=100"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// ResetPasswordRequest represents the payload for completing a reset.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6,max=128"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
 // UserDetail represents the details of a user.
 type UserDetail struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ListUsersRequest represents the query parameters for listing users.
+type ListUsersRequest struct {
+	Cursor      string
+	Limit       int
+	EmailPrefix string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Order       string
+}
+
+// CursorMeta represents pagination metadata for cursor based listings.
+type CursorMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+
+// TOTPEnrollmentResponse represents a pending TOTP enrollment.
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TOTPCodeRequest represents a payload carrying a one-time or recovery code.
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
+}
+
+// TOTPChangeRequest represents the payload for disabling two-factor
+// authentication or replacing the recovery codes. Both the current password
+// and a second factor are required.
+type TOTPChangeRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	// Client is set by the handler.
+	Client ClientInfo `json:"-"`
 }
 
-// GetUserResponse represents the response for fetching users.
-type GetUserResponse struct {
-	Users []UserDetail `json:"users"`
+// RecoveryCodesResponse represents freshly generated recovery codes. They are
+// only returned once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"github.com/google/uuid"
+)
+
+var (
+	errNotAnAdmin       = shared.NewDomainError("ADMIN_ACCESS_DENIED", 403, "account has no admin permissions")
+	errAdminTwoFactor   = shared.NewDomainError("TWO_FACTOR_REQUIRED", 403, "admins must enable two-factor authentication")
+	errCannotModifySelf = shared.NewDomainError("CANNOT_MODIFY_SELF", 409, "admins cannot change their own account through the admin API")
+)
+
+type AdminServiceImpl struct {
+	users    user.UserService
+	userRepo user.UserRepository
+	roles    rbac.RoleRepository
+	audit    audit.AuditRepository
+	tokens   auth.AdminTokenManager
+	throttle auth.LoginThrottler
+}
+
+func NewAdminService(
+	users user.UserService,
+	userRepo user.UserRepository,
+	roles rbac.RoleRepository,
+	audit audit.AuditRepository,
+	tokens auth.AdminTokenManager,
+	throttle auth.LoginThrottler,
+) *AdminServiceImpl {
+	return &AdminServiceImpl{
+		users:    users,
+		userRepo: userRepo,
+		roles:    roles,
+		audit:    audit,
+		tokens:   tokens,
+		throttle: throttle,
+	}
+}
+
+// Compile-time interface checks
+var (
+	_ rbac.AdminService    = (*AdminServiceImpl)(nil)
+	_ router.TokenVerifier = (*AdminServiceImpl)(nil)
+)
+
+// Login checks the credentials of an admin. Admin sign-in always requires a
+// second factor, so on success only an MFA challenge is returned.
+func (s *AdminServiceImpl) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, *shared.DomainError) {
+	u, err := throttled(s.throttle, req.Client.IP, req.Email, func() (*user.User, *shared.DomainError) {
+		return s.users.Authenticate(ctx, req.Email, req.Password)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.adminPermissions(ctx, u); err != nil {
+		return nil, err
+	}
+
+	challenge, issueErr := s.tokens.IssueMFAToken(u)
+	if issueErr != nil {
+		return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue mfa token")
+	}
+	return &dto.LoginResponse{MFARequired: true, MFAToken: challenge.Token}, nil
+}
+
+// LoginTwoFactor completes an admin login and issues an admin token carrying
+// the admin's current permissions.
+func (s *AdminServiceImpl) LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.AdminTokenResponse, *shared.DomainError) {
+	u, err := verifyTwoFactorLogin(ctx, s.throttle, s.users, s.tokens.VerifyMFAToken, req)
+	if err != nil {
+		return nil, err
+	}
+	if u.Disabled() {
+		return nil, errAccountDisabled
+	}
+	permissions, err := s.adminPermissions(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	access, issueErr := s.tokens.IssueAdminToken(u, permissions)
+	if issueErr != nil {
+		return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue admin token")
+	}
+	return &dto.AdminTokenResponse{
+		AccessToken: access.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(access.ExpiresAt).Seconds()),
+		Permissions: permissions,
+	}, nil
+}
+
+// VerifyToken validates an admin token for the admin auth middleware. Admin
+// tokens have no session to revoke, so every request re-checks that the
+// admin is still enabled and uses their current permissions instead of the
+// ones in the token. Disabling an admin or removing a role takes effect
+// immediately.
+func (s *AdminServiceImpl) VerifyToken(ctx context.Context, token string) (*router.Principal, error) {
+	claims, err := s.tokens.VerifyAdminToken(token)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, getErr := s.userRepo.GetUserByID(shared.WithPrimaryReads(ctx), id)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if u.Disabled() {
+		return nil, errAccountDisabled
+	}
+	permissions, permErr := s.adminPermissions(ctx, u)
+	if permErr != nil {
+		return nil, permErr
+	}
+	return &router.Principal{
+		UserID:      claims.UserID,
+		Email:       u.Email,
+		TokenID:     claims.TokenID,
+		ExpiresAt:   claims.ExpiresAt,
+		Permissions: permissions,
+	}, nil
+}
+
+func (s *AdminServiceImpl) ListUsers(ctx context.Context, req *dto.ListUsersRequest) (*dto.AdminUserListResponse, *dto.CursorMeta, *shared.DomainError) {
+	page, meta, err := listUsers(ctx, s.userRepo, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(page))
+	for i := range page {
+		ids[i] = page[i].ID
+	}
+	roles, err := s.roles.GetUserRoles(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make([]dto.AdminUserDetail, len(page))
+	for i := range page {
+		users[i] = *toAdminUserDetail(&page[i], roles[page[i].ID])
+	}
+	return &dto.AdminUserListResponse{Users: users}, meta, nil
+}
+
+func (s *AdminServiceImpl) GetUser(ctx context.Context, id uuid.UUID) (*dto.AdminUserDetail, *shared.DomainError) {
+	u, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roles.GetUserRoles(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserDetail(u, roles[id]), nil
+}
+
+func (s *AdminServiceImpl) GetUserByEmail(ctx context.Context, email string) (*dto.AdminUserDetail, *shared.DomainError) {
+	u, err := s.userRepo.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roles.GetUserRoles(ctx, []uuid.UUID{u.ID})
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserDetail(u, roles[u.ID]), nil
+}
+
+func (s *AdminServiceImpl) DisableUser(ctx context.Context, actorID, id uuid.UUID, client dto.ClientInfo) (*dto.AdminUserDetail, *shared.DomainError) {
+	if actorID == id {
+		return nil, errCannotModifySelf
+	}
+	if err := s.userRepo.SetDisabled(ctx, id, true, auditActor(actorID, client)); err != nil {
+		return nil, err
+	}
+	return s.GetUser(shared.WithPrimaryReads(ctx), id)
+}
+
+func (s *AdminServiceImpl) EnableUser(ctx context.Context, actorID, id uuid.UUID, client dto.ClientInfo) (*dto.AdminUserDetail, *shared.DomainError) {
+	if err := s.userRepo.SetDisabled(ctx, id, false, auditActor(actorID, client)); err != nil {
+		return nil, err
+	}
+	return s.GetUser(shared.WithPrimaryReads(ctx), id)
+}
+
+func (s *AdminServiceImpl) SetUserRoles(ctx context.Context, actorID, id uuid.UUID, req *dto.SetUserRolesRequest) (*dto.AdminUserDetail, *shared.DomainError) {
+	if actorID == id {
+		return nil, errCannotModifySelf
+	}
+	roles := slices.Compact(slices.Sorted(slices.Values(req.Roles)))
+	if err := s.roles.SetUserRoles(ctx, id, roles, auditActor(actorID, req.Client)); err != nil {
+		return nil, err
+	}
+	return s.GetUser(shared.WithPrimaryReads(ctx), id)
+}
+
+func (s *AdminServiceImpl) ListRoles(ctx context.Context) (*dto.RoleListResponse, *shared.DomainError) {
+	roles, err := s.roles.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]dto.RoleDetail, len(*roles))
+	for i, r := range *roles {
+		details[i] = dto.RoleDetail{
+			Name:        r.Name,
+			Description: r.Description,
+			Permissions: r.Permissions,
+		}
+	}
+	return &dto.RoleListResponse{Roles: details}, nil
+}
+
+func (s *AdminServiceImpl) ListAuditLog(ctx context.Context, req *dto.ListAuditLogRequest) (*dto.AuditLogResponse, *dto.CursorMeta, *shared.DomainError) {
+	filter := &audit.Filter{
+		UserID:  req.UserID,
+		ActorID: req.ActorID,
+		From:    req.From,
+		To:      req.To,
+		Limit:   pageSize(req.Limit),
+	}
+	if req.Cursor != "" {
+		after, ok := decodeAuditCursor(req.Cursor)
+		if !ok {
+			return nil, nil, shared.NewDomainError("INVALID_CURSOR", 400, "cursor is invalid")
+		}
+		filter.After = after
+	}
+
+	entries, err := s.audit.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := *entries
+	meta := &dto.CursorMeta{Limit: filter.Limit}
+	if len(page) > filter.Limit {
+		page = page[:filter.Limit]
+		meta.HasMore = true
+		meta.NextCursor = encodeAuditCursor(&page[len(page)-1])
+	}
+
+	details := make([]dto.AuditEntryDetail, len(page))
+	for i, e := range page {
+		details[i] = dto.AuditEntryDetail{
+			ID:        e.ID.String(),
+			UserID:    e.UserID.String(),
+			ActorID:   e.ActorID.String(),
+			Action:    e.Action,
+			IP:        e.IP,
+			RequestID: e.RequestID,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return &dto.AuditLogResponse{Entries: details}, meta, nil
+}
+
+// adminPermissions returns the user's permissions, rejecting users without
+// any permission or without two-factor authentication.
+func (s *AdminServiceImpl) adminPermissions(ctx context.Context, u *user.User) ([]string, *shared.DomainError) {
+	permissions, err := s.roles.GetUserPermissions(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) == 0 {
+		return nil, errNotAnAdmin
+	}
+	if !u.TwoFactorEnabled() {
+		return nil, errAdminTwoFactor
+	}
+	return permissions, nil
+}
+
+func toAdminUserDetail(u *user.User, roles []string) *dto.AdminUserDetail {
+	if roles == nil {
+		roles = []string{}
+	}
+	return &dto.AdminUserDetail{
+		UserDetail: *toUserDetail(u),
+		Disabled:   u.Disabled(),
+		DisabledAt: u.DisabledAt,
+		Roles:      roles,
+	}
+}
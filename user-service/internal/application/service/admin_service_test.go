@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// fakeAdminTokens accepts every token as one issued to userID with the
+// given permissions.
+type fakeAdminTokens struct {
+	auth.AdminTokenManager
+	userID      uuid.UUID
+	permissions []string
+}
+
+func (f *fakeAdminTokens) VerifyAdminToken(token string) (*auth.Claims, error) {
+	if token != "admin-token" {
+		return nil, errors.New("invalid token")
+	}
+	return &auth.Claims{UserID: f.userID.String(), Permissions: f.permissions, ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+type fakeRoles struct {
+	rbac.RoleRepository
+	permissions map[uuid.UUID][]string
+}
+
+func (f *fakeRoles) GetUserPermissions(_ context.Context, id uuid.UUID) ([]string, *shared.DomainError) {
+	return f.permissions[id], nil
+}
+
+func TestAdminVerifyTokenUsesCurrentState(t *testing.T) {
+	now := time.Now()
+	tokenPermissions := []string{rbac.PermUsersRead, rbac.PermUsersDisable}
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		permissions []string
+		want        []string
+		wantErr     bool
+	}{
+		{"unchanged admin", false, tokenPermissions, tokenPermissions, false},
+		{"role removed since sign-in", false, []string{rbac.PermUsersRead}, []string{rbac.PermUsersRead}, false},
+		{"no admin role left", false, nil, nil, true},
+		{"disabled since sign-in", true, tokenPermissions, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &user.User{ID: uuid.New(), Email: "admin@example.com", TOTPEnabledAt: &now}
+			if tt.disabled {
+				u.DisabledAt = &now
+			}
+			s := NewAdminService(nil,
+				&fakeUserRepo{users: map[uuid.UUID]*user.User{u.ID: u}},
+				&fakeRoles{permissions: map[uuid.UUID][]string{u.ID: tt.permissions}},
+				nil,
+				&fakeAdminTokens{userID: u.ID, permissions: tokenPermissions},
+				nil)
+
+			principal, err := s.VerifyToken(context.Background(), "admin-token")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("VerifyToken = %+v, want an error", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyToken: %v", err)
+			}
+			if !slices.Equal(principal.Permissions, tt.want) {
+				t.Errorf("permissions = %v, want %v", principal.Permissions, tt.want)
+			}
+		})
+	}
+}
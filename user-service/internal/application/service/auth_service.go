@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"github.com/google/uuid"
+)
+
+var errInvalidMFAToken = shared.NewDomainError("INVALID_MFA_TOKEN", 401, "mfa token is invalid or expired")
+
+type AuthServiceImpl struct {
+	users         user.UserService
+	userRepo      user.UserRepository
+	refreshTokens auth.RefreshTokenRepository
+	sessions      auth.SessionRepository
+	oauth         auth.OAuthRepository
+	providers     auth.OAuthProviders
+	tokens        auth.TokenManager
+	throttle      auth.LoginThrottler
+}
+
+func NewAuthService(
+	users user.UserService,
+	userRepo user.UserRepository,
+	refreshTokens auth.RefreshTokenRepository,
+	sessions auth.SessionRepository,
+	oauth auth.OAuthRepository,
+	providers auth.OAuthProviders,
+	tokens auth.TokenManager,
+	throttle auth.LoginThrottler,
+) *AuthServiceImpl {
+	return &AuthServiceImpl{
+		users:         users,
+		userRepo:      userRepo,
+		refreshTokens: refreshTokens,
+		sessions:      sessions,
+		oauth:         oauth,
+		providers:     providers,
+		tokens:        tokens,
+		throttle:      throttle,
+	}
+}
+
+// Compile-time interface checks
+var (
+	_ auth.AuthService     = (*AuthServiceImpl)(nil)
+	_ router.TokenVerifier = (*AuthServiceImpl)(nil)
+)
+
+func (s *AuthServiceImpl) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, *shared.DomainError) {
+	u, err := throttled(s.throttle, req.Client.IP, req.Email, func() (*user.User, *shared.DomainError) {
+		return s.users.Authenticate(ctx, req.Email, req.Password)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TwoFactorEnabled() {
+		challenge, issueErr := s.tokens.IssueMFAToken(u)
+		if issueErr != nil {
+			return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue mfa token")
+		}
+		return &dto.LoginResponse{MFARequired: true, MFAToken: challenge.Token}, nil
+	}
+
+	tokens, err := s.startSession(ctx, u, req.Client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{TokenResponse: tokens}, nil
+}
+
+// LoginTwoFactor completes a login started by Login for a user with
+// two-factor authentication enabled. Code guesses are throttled per user ID,
+// separately from password guesses.
+func (s *AuthServiceImpl) LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.TokenResponse, *shared.DomainError) {
+	u, err := verifyTwoFactorLogin(ctx, s.throttle, s.users, s.tokens.VerifyMFAToken, req)
+	if err != nil {
+		return nil, err
+	}
+	if u.Disabled() {
+		return nil, errAccountDisabled
+	}
+	return s.startSession(ctx, u, req.Client)
+}
+
+func (s *AuthServiceImpl) Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.TokenResponse, *shared.DomainError) {
+	refresh, issueErr := s.tokens.IssueRefreshToken()
+	if issueErr != nil {
+		return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue refresh token")
+	}
+
+	next := &auth.RefreshToken{
+		TokenHash: refresh.Hash,
+		ExpiresAt: refresh.ExpiresAt,
+	}
+	if err := s.refreshTokens.RotateRefreshToken(ctx, s.tokens.HashToken(req.RefreshToken), next, req.Client.IP); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetUserByID(ctx, next.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenResponse(u, next.FamilyID, refresh)
+}
+
+// VerifyToken validates an access token for the auth middleware. Tokens of
+// revoked or expired sessions are rejected even if the token itself is valid.
+func (s *AuthServiceImpl) VerifyToken(ctx context.Context, token string) (*router.Principal, error) {
+	claims, err := s.tokens.VerifyAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
+	return &router.Principal{
+		UserID:        claims.UserID,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		TokenID:       claims.TokenID,
+		SessionID:     claims.SessionID,
+		ExpiresAt:     claims.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthServiceImpl) JWKS() *dto.JWKSResponse {
+	return s.tokens.JWKS()
+}
+
+// startSession starts a new session on the client's device and issues the
+// first tokens.
+func (s *AuthServiceImpl) startSession(ctx context.Context, u *user.User, client dto.ClientInfo) (*dto.TokenResponse, *shared.DomainError) {
+	refresh, issueErr := s.tokens.IssueRefreshToken()
+	if issueErr != nil {
+		return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue refresh token")
+	}
+	session := &auth.Session{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IP:        client.IP,
+		ExpiresAt: refresh.ExpiresAt,
+	}
+	err := s.sessions.CreateSession(ctx, session, &auth.RefreshToken{
+		TokenHash: refresh.Hash,
+		ExpiresAt: refresh.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenResponse(u, session.ID, refresh)
+}
+
+func (s *AuthServiceImpl) tokenResponse(u *user.User, sessionID uuid.UUID, refresh *auth.OpaqueToken) (*dto.TokenResponse, *shared.DomainError) {
+	access, err := s.tokens.IssueAccessToken(u, sessionID)
+	if err != nil {
+		return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue access token")
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:      access.Token,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(time.Until(access.ExpiresAt).Seconds()),
+		RefreshToken:     refresh.Token,
+		RefreshExpiresIn: int64(time.Until(refresh.ExpiresAt).Seconds()),
+	}, nil
+}
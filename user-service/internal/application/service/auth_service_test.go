@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// fakeTokens issues predictable tokens. Methods not needed by the tests are
+// left to the embedded nil interface.
+type fakeTokens struct {
+	auth.TokenManager
+	issued int
+}
+
+func (f *fakeTokens) IssueAccessToken(u *user.User, sessionID uuid.UUID) (*auth.AccessToken, error) {
+	return &auth.AccessToken{Token: "access:" + sessionID.String(), ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+func (f *fakeTokens) IssueRefreshToken() (*auth.OpaqueToken, error) {
+	f.issued++
+	token := uuid.NewString()
+	return &auth.OpaqueToken{Token: token, Hash: "hash:" + token, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (f *fakeTokens) HashToken(token string) string { return "hash:" + token }
+
+// fakeRefreshTokens keeps one refresh token family in memory and applies
+// the same rotation rules as the database repository.
+type fakeRefreshTokens struct {
+	auth.RefreshTokenRepository
+	tokens  map[string]*auth.RefreshToken
+	revoked bool
+}
+
+func (f *fakeRefreshTokens) RotateRefreshToken(_ context.Context, hash string, next *auth.RefreshToken, _ string) *shared.DomainError {
+	current, ok := f.tokens[hash]
+	switch {
+	case !ok || f.revoked:
+		return shared.NewDomainError("INVALID_REFRESH_TOKEN", 401, "refresh token is invalid")
+	case current.RotatedAt != nil:
+		f.revoked = true
+		return shared.NewDomainError("REFRESH_TOKEN_REUSED", 401, "refresh token has already been used")
+	}
+	now := time.Now()
+	current.RotatedAt = &now
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	f.tokens[next.TokenHash] = next
+	return nil
+}
+
+type fakeSessions struct{ auth.SessionRepository }
+
+func (fakeSessions) CreateSession(context.Context, *auth.Session, *auth.RefreshToken) *shared.DomainError {
+	return nil
+}
+
+type fakeUserRepo struct {
+	user.UserRepository
+	users map[uuid.UUID]*user.User
+}
+
+func (f *fakeUserRepo) GetUserByID(_ context.Context, id uuid.UUID) (*user.User, *shared.DomainError) {
+	if u, ok := f.users[id]; ok {
+		return u, nil
+	}
+	return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+}
+
+func (f *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (*user.User, *shared.DomainError) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+}
+
+func TestRefreshDetectsReuse(t *testing.T) {
+	u := &user.User{ID: uuid.New(), Email: "jane@example.com"}
+	familyID := uuid.New()
+
+	refreshTokens := &fakeRefreshTokens{tokens: map[string]*auth.RefreshToken{
+		"hash:first": {UserID: u.ID, FamilyID: familyID, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	s := NewAuthService(nil, &fakeUserRepo{users: map[uuid.UUID]*user.User{u.ID: u}},
+		refreshTokens, fakeSessions{}, nil, nil, &fakeTokens{}, nil)
+	refresh := func(token string) (*dto.TokenResponse, *shared.DomainError) {
+		return s.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token})
+	}
+
+	second, err := refresh("first")
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if second.RefreshToken == "first" || second.AccessToken != "access:"+familyID.String() {
+		t.Fatalf("first refresh = %+v, want a new refresh token in session %s", second, familyID)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  string
+	}{
+		{"replaying the rotated token", "first", "REFRESH_TOKEN_REUSED"},
+		// The replay revoked the family, so the legitimate client's token
+		// stops working too.
+		{"using the latest token after a replay", second.RefreshToken, "INVALID_REFRESH_TOKEN"},
+		{"unknown token", "unknown", "INVALID_REFRESH_TOKEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := refresh(tt.token)
+			if resp != nil || err == nil || err.Code != tt.code {
+				t.Errorf("Refresh = %+v, %v; want nil, %s", resp, err, tt.code)
+			}
+		})
+	}
+}
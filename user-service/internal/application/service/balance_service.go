@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+)
+
+type BalanceServiceImpl struct {
+	repo balance.BalanceRepository
+}
+
+func NewBalanceService(repo balance.BalanceRepository) *BalanceServiceImpl {
+	return &BalanceServiceImpl{
+		repo: repo,
+	}
+}
+
+// Compile-time interface check
+var _ balance.BalanceService = (*BalanceServiceImpl)(nil)
+
+func (s *BalanceServiceImpl) GetBalance(ctx context.Context, userID uuid.UUID) (*dto.BalanceResponse, *shared.DomainError) {
+	account, err := s.repo.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.BalanceResponse{
+		Balance:   account.Amount,
+		UpdatedAt: account.UpdatedAt,
+	}, nil
+}
+
+// ListTransactions returns one page of the user's balance movements, newest
+// first, and the cursor for the next page.
+func (s *BalanceServiceImpl) ListTransactions(ctx context.Context, userID uuid.UUID, req *dto.ListTransactionsRequest) (*dto.TransactionHistoryResponse, *dto.CursorMeta, *shared.DomainError) {
+	filter := &balance.EntryFilter{Limit: pageSize(req.Limit)}
+	if req.Cursor != "" {
+		after, ok := decodeEntryCursor(req.Cursor)
+		if !ok {
+			return nil, nil, shared.NewDomainError("INVALID_CURSOR", 400, "cursor is invalid")
+		}
+		filter.After = after
+	}
+
+	entries, err := s.repo.ListEntries(ctx, userID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := *entries
+	meta := &dto.CursorMeta{Limit: filter.Limit}
+	if len(page) > filter.Limit {
+		page = page[:filter.Limit]
+		meta.HasMore = true
+		meta.NextCursor = encodeEntryCursor(&page[len(page)-1])
+	}
+
+	details := make([]dto.LedgerEntryDetail, len(page))
+	for i := range page {
+		details[i] = *toLedgerEntryDetail(&page[i])
+	}
+	return &dto.TransactionHistoryResponse{Transactions: details}, meta, nil
+}
+
+// Credit adds funds to the user's balance.
+func (s *BalanceServiceImpl) Credit(ctx context.Context, actorID, userID uuid.UUID, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError) {
+	return s.post(ctx, actorID, userID, balance.TypeCredit, req.Amount, req)
+}
+
+// Debit removes funds from the user's balance. It fails with
+// INSUFFICIENT_FUNDS rather than letting the balance go negative.
+func (s *BalanceServiceImpl) Debit(ctx context.Context, actorID, userID uuid.UUID, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError) {
+	return s.post(ctx, actorID, userID, balance.TypeDebit, -req.Amount, req)
+}
+
+func (s *BalanceServiceImpl) post(ctx context.Context, actorID, userID uuid.UUID, txnType string, amount int64, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError) {
+	if req.Amount <= 0 {
+		return nil, shared.NewDomainError("INVALID_AMOUNT", 400, "amount must be positive")
+	}
+	entry, err := s.repo.Post(ctx, userID, amount, &balance.Transaction{
+		Type:        txnType,
+		Reference:   strings.TrimSpace(req.Reference),
+		Description: strings.TrimSpace(req.Description),
+	}, auditActor(actorID, req.Client))
+	if err != nil {
+		return nil, err
+	}
+	return toLedgerEntryDetail(entry), nil
+}
+
+func toLedgerEntryDetail(e *balance.Entry) *dto.LedgerEntryDetail {
+	detail := &dto.LedgerEntryDetail{
+		ID:            e.ID.String(),
+		TransactionID: e.TransactionID.String(),
+		Amount:        e.Amount,
+		CreatedAt:     e.CreatedAt,
+	}
+	if e.BalanceAfter != nil {
+		detail.BalanceAfter = *e.BalanceAfter
+	}
+	if e.Transaction != nil {
+		detail.Type = e.Transaction.Type
+		detail.Reference = e.Transaction.Reference
+		detail.Description = e.Transaction.Description
+	}
+	return detail
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageSize applies the default and upper bound to a requested page size.
+func pageSize(limit int) int {
+	switch {
+	case limit <= 0:
+		return defaultPageSize
+	case limit > maxPageSize:
+		return maxPageSize
+	}
+	return limit
+}
+
+// userCursor is the opaque cursor handed to clients. It records the sort it
+// was issued for so it cannot be replayed against a different ordering.
+type userCursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// entryCursor is the opaque cursor for the transaction history and the
+// audit log.
+type entryCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func encodeUserCursor(c *user.UserCursor, sort string, desc bool) string {
+	return encodeCursor(userCursor{Sort: sort, Desc: desc, Value: c.SortValue, ID: c.ID})
+}
+
+func decodeUserCursor(s, sort string, desc bool) (*user.UserCursor, bool) {
+	var c userCursor
+	if !decodeCursor(s, &c) || c.Sort != sort || c.Desc != desc || c.ID == uuid.Nil {
+		return nil, false
+	}
+	return &user.UserCursor{SortValue: c.Value, ID: c.ID}, true
+}
+
+func encodeEntryCursor(e *balance.Entry) string {
+	return encodeCursor(entryCursor{CreatedAt: e.CreatedAt, ID: e.ID})
+}
+
+func decodeEntryCursor(s string) (*balance.EntryCursor, bool) {
+	var c entryCursor
+	if !decodeCursor(s, &c) || c.ID == uuid.Nil {
+		return nil, false
+	}
+	return &balance.EntryCursor{CreatedAt: c.CreatedAt, ID: c.ID}, true
+}
+
+func encodeAuditCursor(e *audit.Entry) string {
+	return encodeCursor(entryCursor{CreatedAt: e.CreatedAt, ID: e.ID})
+}
+
+func decodeAuditCursor(s string) (*audit.Cursor, bool) {
+	var c entryCursor
+	if !decodeCursor(s, &c) || c.ID == uuid.Nil {
+		return nil, false
+	}
+	return &audit.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}, true
+}
+
+func encodeCursor(v any) string {
+	raw, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string, v any) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
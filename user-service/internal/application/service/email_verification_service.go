@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidVerificationToken = shared.NewDomainError("INVALID_VERIFICATION_TOKEN", 400, "verification token is invalid or expired")
+	errEmailAlreadyVerified     = shared.NewDomainError("EMAIL_ALREADY_VERIFIED", 409, "email is already verified")
+)
+
+// VerifyEmail confirms the address the token was issued for. Verifying an
+// already verified address succeeds so that links can be clicked twice.
+func (s *UserServiceImpl) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserDetail, *shared.DomainError) {
+	id, email, verifyErr := s.tokens.VerifyEmailVerificationToken(req.Token)
+	if verifyErr != nil {
+		return nil, errInvalidVerificationToken
+	}
+
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		if err.StatusCode == 404 {
+			return nil, errInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if u.Email != email {
+		return nil, errInvalidVerificationToken
+	}
+	if u.EmailVerified() {
+		return toUserDetail(u), nil
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, id, email); err != nil {
+		return nil, err
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+// ResendVerificationEmail mails a new verification link. Every request
+// counts against the client IP and the account, since each one sends an
+// email.
+func (s *UserServiceImpl) ResendVerificationEmail(ctx context.Context, id uuid.UUID, client dto.ClientInfo) *shared.DomainError {
+	if err := countRequest(s.throttle, client.IP, emailVerificationAccount(id)); err != nil {
+		return err
+	}
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified() {
+		return errEmailAlreadyVerified
+	}
+	return s.sendVerificationEmail(u)
+}
+
+func (s *UserServiceImpl) sendVerificationEmail(u *user.User) *shared.DomainError {
+	token, err := s.tokens.IssueEmailVerificationToken(u)
+	if err != nil {
+		return shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue verification token")
+	}
+	if err := s.notifier.SendEmailVerification(u, token); err != nil {
+		return shared.NewDomainError("MAIL_DELIVERY_FAILED", 502, "failed to send verification email")
+	}
+	return nil
+}
+
+// emailVerificationAccount keeps verification mails, which are sent on
+// request and on every email change, from using up the sign-in attempts of
+// the account.
+func emailVerificationAccount(id uuid.UUID) string {
+	return "email_verification:" + id.String()
+}
@@ -1,17 +1,57 @@
 package service
 
 import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
 	"go.uber.org/fx"
 )
 
 var Module = fx.Module(
 	"service",
 	fx.Provide(
-		NewUserService,
+		// One instance, so that shutdown waits for the background work of
+		// the service the handlers use.
 		fx.Annotate(
 			NewUserService,
+			fx.As(fx.Self()),
 			fx.As(new(user.UserService)),
 		),
+		NewAuthService,
+		fx.Annotate(
+			NewAuthService,
+			fx.As(new(auth.AuthService)),
+		),
+		fx.Annotate(
+			NewAuthService,
+			fx.As(new(router.TokenVerifier)),
+		),
+		NewBalanceService,
+		fx.Annotate(
+			NewBalanceService,
+			fx.As(new(balance.BalanceService)),
+		),
+		NewAdminService,
+		fx.Annotate(
+			NewAdminService,
+			fx.As(new(rbac.AdminService)),
+		),
+		// Verifies admin tokens for the admin auth middleware.
+		fx.Annotate(
+			NewAdminService,
+			fx.As(new(router.TokenVerifier)),
+			fx.ResultTags(`name:"admin"`),
+		),
 	),
+	fx.Invoke(waitOnStop),
 )
+
+// waitOnStop lets password reset mails that are still being sent finish
+// before the database and mailer shut down.
+func waitOnStop(lc fx.Lifecycle, users *UserServiceImpl) {
+	lc.Append(fx.Hook{
+		OnStop: users.Wait,
+	})
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// oauthStateTTL bounds how long a user may take to authorize at the provider.
+const oauthStateTTL = 10 * time.Minute
+
+var (
+	errUnknownProvider   = shared.NewDomainError("UNKNOWN_OAUTH_PROVIDER", 404, "oauth provider is not configured")
+	errOAuthLinkRequired = shared.NewDomainError("OAUTH_LINK_REQUIRED", 409, "an account with this email already exists; sign in with your password and link the provider from your account")
+	errIdentityLinked    = shared.NewDomainError("IDENTITY_ALREADY_LINKED", 409, "identity is already linked to an account")
+)
+
+// StartOAuth creates a pending authorization request and returns the
+// provider URL the user agent should be redirected to.
+func (s *AuthServiceImpl) StartOAuth(ctx context.Context, provider string) (*dto.OAuthStartResponse, *shared.DomainError) {
+	return s.startOAuth(ctx, provider, uuid.Nil)
+}
+
+// StartOAuthLink creates a pending authorization request bound to userID.
+// Only the user who started it can be linked, since the state is stored
+// server-side and also has to match the browser's state cookie.
+func (s *AuthServiceImpl) StartOAuthLink(ctx context.Context, userID uuid.UUID, provider string) (*dto.OAuthStartResponse, *shared.DomainError) {
+	return s.startOAuth(ctx, provider, userID)
+}
+
+func (s *AuthServiceImpl) startOAuth(ctx context.Context, provider string, userID uuid.UUID) (*dto.OAuthStartResponse, *shared.DomainError) {
+	client, ok := s.providers.Provider(provider)
+	if !ok {
+		return nil, errUnknownProvider
+	}
+
+	state, stateErr := randomToken()
+	verifier, verifierErr := randomToken()
+	nonce, nonceErr := randomToken()
+	if stateErr != nil || verifierErr != nil || nonceErr != nil {
+		return nil, shared.NewDomainError("OAUTH_START_FAILED", 500, "failed to generate oauth state")
+	}
+
+	err := s.oauth.CreateState(ctx, &auth.OAuthState{
+		StateHash:    s.tokens.HashToken(state),
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().UTC().Add(oauthStateTTL),
+		UserID:       userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthStartResponse{
+		URL:       client.AuthCodeURL(state, verifier, nonce),
+		State:     state,
+		ExpiresIn: int64(oauthStateTTL.Seconds()),
+	}, nil
+}
+
+// CompleteOAuth validates the state, exchanges the code and signs in the
+// linked user, creating an account on first login. For a state started by
+// StartOAuthLink the identity is linked to that user instead.
+func (s *AuthServiceImpl) CompleteOAuth(ctx context.Context, provider, state, code string, device dto.ClientInfo) (*dto.LoginResponse, *shared.DomainError) {
+	client, ok := s.providers.Provider(provider)
+	if !ok {
+		return nil, errUnknownProvider
+	}
+
+	pending, err := s.oauth.ConsumeState(ctx, s.tokens.HashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if pending.Provider != provider || time.Now().UTC().After(pending.ExpiresAt) {
+		return nil, shared.NewDomainError("INVALID_OAUTH_STATE", 400, "oauth state is invalid or was already used")
+	}
+
+	profile, exchangeErr := client.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if exchangeErr != nil {
+		return nil, shared.NewDomainError("OAUTH_EXCHANGE_FAILED", 502, "failed to complete sign in with provider")
+	}
+
+	if pending.UserID != uuid.Nil {
+		if err := s.linkOAuthIdentity(shared.WithPrimaryReads(ctx), pending.UserID, provider, profile, device); err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{Linked: true}, nil
+	}
+
+	u, err := s.resolveOAuthUser(shared.WithPrimaryReads(ctx), provider, profile, device)
+	if err != nil {
+		return nil, err
+	}
+	if u.Disabled() {
+		return nil, errAccountDisabled
+	}
+
+	if u.TwoFactorEnabled() {
+		challenge, issueErr := s.tokens.IssueMFAToken(u)
+		if issueErr != nil {
+			return nil, shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to issue mfa token")
+		}
+		return &dto.LoginResponse{MFARequired: true, MFAToken: challenge.Token}, nil
+	}
+
+	tokens, err := s.startSession(ctx, u, device)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{TokenResponse: tokens}, nil
+}
+
+// resolveOAuthUser returns the user linked to the external identity or
+// creates a new account for an unknown identity. An unknown identity is never
+// linked to an existing account with the same email: the provider's word
+// that the address is verified does not prove the caller owns the account.
+func (s *AuthServiceImpl) resolveOAuthUser(ctx context.Context, provider string, profile *auth.ExternalProfile, device dto.ClientInfo) (*user.User, *shared.DomainError) {
+	identity, err := s.oauth.GetIdentity(ctx, provider, profile.Subject)
+	if err == nil {
+		return s.userRepo.GetUserByID(ctx, identity.UserID)
+	}
+	if err.StatusCode != 404 {
+		return nil, err
+	}
+
+	if profile.Email == "" {
+		return nil, shared.NewDomainError("OAUTH_EMAIL_REQUIRED", 422, "provider did not share an email address")
+	}
+
+	identity = &auth.Identity{
+		Provider: provider,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	}
+
+	_, err = s.userRepo.GetUserByEmail(ctx, profile.Email)
+	switch {
+	case err == nil:
+		return nil, errOAuthLinkRequired
+	case err.StatusCode != 404:
+		return nil, err
+	}
+
+	name := profile.Name
+	if name == "" {
+		name, _, _ = strings.Cut(profile.Email, "@")
+	}
+	// Social-only accounts have no usable password until one is set
+	u := &user.User{Name: name, Email: profile.Email}
+	if profile.EmailVerified {
+		now := time.Now().UTC()
+		u.EmailVerifiedAt = &now
+	}
+	if err := s.oauth.CreateUserWithIdentity(ctx, u, identity, auditActor(uuid.Nil, device)); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// linkOAuthIdentity links the external identity to userID. Linking an
+// identity that is already linked to the same user is a no-op.
+func (s *AuthServiceImpl) linkOAuthIdentity(ctx context.Context, userID uuid.UUID, provider string, profile *auth.ExternalProfile, device dto.ClientInfo) *shared.DomainError {
+	identity, err := s.oauth.GetIdentity(ctx, provider, profile.Subject)
+	switch {
+	case err == nil && identity.UserID == userID:
+		return nil
+	case err == nil:
+		return errIdentityLinked
+	case err.StatusCode != 404:
+		return err
+	}
+
+	u, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Disabled() {
+		return errAccountDisabled
+	}
+	return s.oauth.LinkIdentity(ctx, &auth.Identity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	}, auditActor(userID, device))
+}
+
+// randomToken returns 32 random bytes encoded as base64url, which also
+// satisfies the RFC 7636 code verifier alphabet and length.
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// fakeOAuthClient returns profile for the verifier and nonce given to the
+// AuthCodeURL call, like a provider enforcing PKCE and echoing the nonce.
+type fakeOAuthClient struct {
+	verifier, nonce string
+	profile         *auth.ExternalProfile
+}
+
+func (f *fakeOAuthClient) AuthCodeURL(state, verifier, nonce string) string {
+	f.verifier, f.nonce = verifier, nonce
+	return "https://provider.test/authorize?" + url.Values{"state": {state}}.Encode()
+}
+
+func (f *fakeOAuthClient) Exchange(_ context.Context, code, verifier, nonce string) (*auth.ExternalProfile, error) {
+	if verifier != f.verifier || nonce != f.nonce {
+		return nil, context.DeadlineExceeded
+	}
+	return f.profile, nil
+}
+
+type fakeProviders map[string]auth.OAuthClient
+
+func (f fakeProviders) Provider(name string) (auth.OAuthClient, bool) {
+	c, ok := f[name]
+	return c, ok
+}
+
+type fakeOAuthRepo struct {
+	auth.OAuthRepository
+	states     map[string]*auth.OAuthState
+	identities map[string]*auth.Identity
+	users      *fakeUserRepo
+}
+
+func (f *fakeOAuthRepo) CreateState(_ context.Context, state *auth.OAuthState) *shared.DomainError {
+	f.states[state.StateHash] = state
+	return nil
+}
+
+func (f *fakeOAuthRepo) ConsumeState(_ context.Context, hash string) (*auth.OAuthState, *shared.DomainError) {
+	state, ok := f.states[hash]
+	if !ok {
+		return nil, shared.NewDomainError("INVALID_OAUTH_STATE", 400, "oauth state is invalid or was already used")
+	}
+	delete(f.states, hash)
+	return state, nil
+}
+
+func (f *fakeOAuthRepo) GetIdentity(_ context.Context, provider, subject string) (*auth.Identity, *shared.DomainError) {
+	if identity, ok := f.identities[provider+":"+subject]; ok {
+		return identity, nil
+	}
+	return nil, shared.NewDomainError("IDENTITY_NOT_FOUND", 404, "identity not found")
+}
+
+func (f *fakeOAuthRepo) CreateUserWithIdentity(_ context.Context, u *user.User, identity *auth.Identity, _ audit.Actor) *shared.DomainError {
+	u.ID = uuid.New()
+	identity.UserID = u.ID
+	f.users.users[u.ID] = u
+	f.identities[identity.Provider+":"+identity.Subject] = identity
+	return nil
+}
+
+func (f *fakeOAuthRepo) LinkIdentity(_ context.Context, identity *auth.Identity, _ audit.Actor) *shared.DomainError {
+	f.identities[identity.Provider+":"+identity.Subject] = identity
+	return nil
+}
+
+func TestCompleteOAuth(t *testing.T) {
+	existing := &user.User{ID: uuid.New(), Email: "jane@example.com"}
+	linked := &user.User{ID: uuid.New(), Email: "john@example.com"}
+
+	tests := []struct {
+		name string
+		// start and complete name the providers used to start and finish the flow
+		start, complete string
+		profile         auth.ExternalProfile
+		// tamper changes the pending state before the callback
+		tamper   func(state *auth.OAuthState)
+		wantCode string
+		wantUser uuid.UUID
+	}{
+		{
+			name: "linked identity", start: "google", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "john", Email: "john@example.com", EmailVerified: true},
+			wantUser: linked.ID,
+		},
+		{
+			name: "new user", start: "google", complete: "google",
+			profile: auth.ExternalProfile{Subject: "new", Email: "new@example.com", EmailVerified: true},
+		},
+		{
+			// A provider account with the victim's address must not sign in
+			// as the victim, even if the provider says it is verified.
+			name: "existing account with the same email", start: "google", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "attacker", Email: existing.Email, EmailVerified: true},
+			wantCode: "OAUTH_LINK_REQUIRED",
+		},
+		{
+			name: "state started for another provider", start: "apple", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "john", Email: "john@example.com"},
+			wantCode: "INVALID_OAUTH_STATE",
+		},
+		{
+			name: "expired state", start: "google", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "john", Email: "john@example.com"},
+			tamper:   func(state *auth.OAuthState) { state.ExpiresAt = time.Now().Add(-time.Second) },
+			wantCode: "INVALID_OAUTH_STATE",
+		},
+		{
+			name: "code verifier of another request", start: "google", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "john", Email: "john@example.com"},
+			tamper:   func(state *auth.OAuthState) { state.CodeVerifier = "other" },
+			wantCode: "OAUTH_EXCHANGE_FAILED",
+		},
+		{
+			name: "nonce of another request", start: "google", complete: "google",
+			profile:  auth.ExternalProfile{Subject: "john", Email: "john@example.com"},
+			tamper:   func(state *auth.OAuthState) { state.Nonce = "other" },
+			wantCode: "OAUTH_EXCHANGE_FAILED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{users: map[uuid.UUID]*user.User{existing.ID: existing, linked.ID: linked}}
+			oauth := &fakeOAuthRepo{
+				states:     map[string]*auth.OAuthState{},
+				identities: map[string]*auth.Identity{"google:john": {UserID: linked.ID, Provider: "google", Subject: "john"}},
+				users:      users,
+			}
+			client := &fakeOAuthClient{profile: &tt.profile}
+			providers := fakeProviders{"google": client, "apple": client}
+			s := NewAuthService(nil, users, nil, fakeSessions{}, oauth, providers, &fakeTokens{}, nil)
+
+			started, err := s.StartOAuth(context.Background(), tt.start)
+			if err != nil {
+				t.Fatalf("StartOAuth: %v", err)
+			}
+			pending := oauth.states[(&fakeTokens{}).HashToken(started.State)]
+			if pending == nil || pending.CodeVerifier == "" || pending.Nonce == "" || pending.CodeVerifier == pending.Nonce {
+				t.Fatalf("pending state = %+v, want a distinct verifier and nonce", pending)
+			}
+			if tt.tamper != nil {
+				tt.tamper(pending)
+			}
+
+			res, err := s.CompleteOAuth(context.Background(), tt.complete, started.State, "code", dto.ClientInfo{})
+			if tt.wantCode != "" {
+				if err == nil || err.Code != tt.wantCode {
+					t.Fatalf("CompleteOAuth = %+v, %v; want %s", res, err, tt.wantCode)
+				}
+				if identity := oauth.identities["google:attacker"]; identity != nil {
+					t.Errorf("identity was linked to %s", identity.UserID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompleteOAuth: %v", err)
+			}
+			identity := oauth.identities["google:"+tt.profile.Subject]
+			if res.AccessToken == "" || identity == nil || (tt.wantUser != uuid.Nil && identity.UserID != tt.wantUser) {
+				t.Errorf("CompleteOAuth = %+v with identity %+v", res, identity)
+			}
+
+			// The state is single use
+			if _, err := s.CompleteOAuth(context.Background(), tt.complete, started.State, "code", dto.ClientInfo{}); err == nil || err.Code != "INVALID_OAUTH_STATE" {
+				t.Errorf("replayed CompleteOAuth = %v, want INVALID_OAUTH_STATE", err)
+			}
+		})
+	}
+
+	s := NewAuthService(nil, nil, nil, nil, nil, fakeProviders{}, &fakeTokens{}, nil)
+	if _, err := s.StartOAuth(context.Background(), "unknown"); err != errUnknownProvider {
+		t.Errorf("StartOAuth(unknown) = %v, want %v", err, errUnknownProvider)
+	}
+}
+
+func TestCompleteOAuthLink(t *testing.T) {
+	jane := &user.User{ID: uuid.New(), Email: "jane@example.com"}
+	john := &user.User{ID: uuid.New(), Email: "john@example.com"}
+
+	tests := []struct {
+		name     string
+		subject  string
+		wantCode string
+		wantUser uuid.UUID
+	}{
+		// The provider account may use any address, including one of another
+		// user, since the signed-in user is linked rather than matched by email.
+		{name: "new identity", subject: "jane-at-google", wantUser: jane.ID},
+		{name: "identity already linked to the user", subject: "jane", wantUser: jane.ID},
+		{name: "identity linked to another user", subject: "john", wantCode: "IDENTITY_ALREADY_LINKED", wantUser: john.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{users: map[uuid.UUID]*user.User{jane.ID: jane, john.ID: john}}
+			oauth := &fakeOAuthRepo{
+				states: map[string]*auth.OAuthState{},
+				identities: map[string]*auth.Identity{
+					"google:jane": {UserID: jane.ID, Provider: "google", Subject: "jane"},
+					"google:john": {UserID: john.ID, Provider: "google", Subject: "john"},
+				},
+				users: users,
+			}
+			client := &fakeOAuthClient{profile: &auth.ExternalProfile{Subject: tt.subject, Email: john.Email, EmailVerified: true}}
+			s := NewAuthService(nil, users, nil, fakeSessions{}, oauth, fakeProviders{"google": client}, &fakeTokens{}, nil)
+
+			started, err := s.StartOAuthLink(context.Background(), jane.ID, "google")
+			if err != nil {
+				t.Fatalf("StartOAuthLink: %v", err)
+			}
+			res, err := s.CompleteOAuth(context.Background(), "google", started.State, "code", dto.ClientInfo{})
+			if tt.wantCode != "" {
+				if err == nil || err.Code != tt.wantCode {
+					t.Fatalf("CompleteOAuth = %+v, %v; want %s", res, err, tt.wantCode)
+				}
+			} else if err != nil || !res.Linked || res.TokenResponse != nil {
+				t.Fatalf("CompleteOAuth = %+v, %v; want a link without a session", res, err)
+			}
+			if identity := oauth.identities["google:"+tt.subject]; identity == nil || identity.UserID != tt.wantUser {
+				t.Errorf("identity = %+v, want it linked to %s", identity, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const passwordResetTTL = time.Hour
+
+// ChangePassword replaces the password after re-checking the current one and
+// signs the user out everywhere. Wrong current passwords count as failed
+// sign-ins of the account, so a stolen session cannot be used to guess it.
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, id uuid.UUID, req *dto.ChangePasswordRequest) *shared.DomainError {
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = throttled(s.throttle, req.Client.IP, u.Email, func() (struct{}, *shared.DomainError) {
+		if ok, _ := s.hasher.Verify(req.CurrentPassword, u.Password); !ok {
+			return struct{}{}, shared.NewDomainError("INVALID_CREDENTIALS", 401, "password is incorrect")
+		}
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	hash, hashErr := s.hasher.Hash(req.NewPassword)
+	if hashErr != nil {
+		return shared.NewDomainError("HASH_FAILED", 500, "failed to hash password")
+	}
+	return s.repo.ChangePassword(ctx, id, hash, auditActor(id, req.Client))
+}
+
+// RequestPasswordReset mails a reset link if an account exists for the
+// address. It reports success either way so it cannot be used to find out
+// which emails are registered. Every request counts against the client IP
+// and the address, since each one may send an email.
+func (s *UserServiceImpl) RequestPasswordReset(ctx context.Context, req *dto.ForgotPasswordRequest) *shared.DomainError {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := countRequest(s.throttle, req.Client.IP, passwordResetAccount(email)); err != nil {
+		return err
+	}
+
+	// The lookup and the mail run in the background, so the response takes
+	// the same time whether or not the address is registered. Failures are
+	// logged rather than reported for the same reason.
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		if err := s.sendPasswordReset(context.WithoutCancel(ctx), email); err != nil {
+			s.log.Error("failed to send password reset",
+				zap.String("request_id", req.Client.RequestID),
+				zap.String("code", err.Code),
+				zap.Error(err.Cause),
+			)
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset issues a reset token for the account of email, if any,
+// and mails it. An unknown email is not an error.
+func (s *UserServiceImpl) sendPasswordReset(ctx context.Context, email string) *shared.DomainError {
+	u, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err.StatusCode == 404 {
+			return nil
+		}
+		return err
+	}
+	token, genErr := randomToken()
+	if genErr != nil {
+		return shared.NewDomainError("TOKEN_ISSUE_FAILED", 500, "failed to generate reset token").WithCause(genErr)
+	}
+	err = s.repo.CreatePasswordResetToken(ctx, &user.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().UTC().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+	if mailErr := s.notifier.SendPasswordReset(u, token); mailErr != nil {
+		return shared.NewDomainError("MAIL_DELIVERY_FAILED", 502, "failed to send password reset email").WithCause(mailErr)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a mailed reset token and signs the
+// user out everywhere. Invalid tokens count as failed attempts of the client
+// IP and are rejected before the new password is hashed, so guessing tokens
+// is cheap for us and throttled.
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) *shared.DomainError {
+	if wait := s.throttle.Check(req.Client.IP, ""); wait > 0 {
+		return errTooManyAttempts(wait)
+	}
+	tokenHash := hashResetToken(strings.TrimSpace(req.Token))
+	if err := s.repo.CheckPasswordResetToken(ctx, tokenHash); err != nil {
+		if err.StatusCode == 400 {
+			if wait := s.throttle.Failure(req.Client.IP, ""); wait > 0 {
+				return errTooManyAttempts(wait)
+			}
+		}
+		return err
+	}
+
+	hash, hashErr := s.hasher.Hash(req.Password)
+	if hashErr != nil {
+		return shared.NewDomainError("HASH_FAILED", 500, "failed to hash password")
+	}
+	// The token is consumed atomically here, in case it was used meanwhile
+	_, err := s.repo.ResetPassword(ctx, tokenHash, hash, auditActor(uuid.Nil, req.Client))
+	return err
+}
+
+// passwordResetAccount keeps reset requests from using up the sign-in
+// attempts of the account, which would let anyone lock its owner out.
+func passwordResetAccount(email string) string {
+	return "password_reset:" + email
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type countingHasher struct {
+	user.PasswordHasher
+	hashed int
+}
+
+func (h *countingHasher) Hash(password string) (string, error) {
+	h.hashed++
+	return "hashed:" + password, nil
+}
+
+func (h *countingHasher) Verify(password, encoded string) (bool, error) {
+	return encoded == "hashed:"+password, nil
+}
+
+// resetTokenRepo holds valid reset token hashes in memory.
+type resetTokenRepo struct {
+	fakeUserRepo
+	tokens map[string]bool
+}
+
+func (r *resetTokenRepo) CheckPasswordResetToken(_ context.Context, tokenHash string) *shared.DomainError {
+	if !r.tokens[tokenHash] {
+		return shared.NewDomainError("INVALID_RESET_TOKEN", 400, "reset token is invalid or expired")
+	}
+	return nil
+}
+
+func (r *resetTokenRepo) ResetPassword(_ context.Context, tokenHash, _ string, _ audit.Actor) (uuid.UUID, *shared.DomainError) {
+	delete(r.tokens, tokenHash)
+	return uuid.New(), nil
+}
+
+func TestResetPasswordChecksTokenBeforeHashing(t *testing.T) {
+	repo := &resetTokenRepo{tokens: map[string]bool{hashResetToken("valid"): true}}
+	hasher := &countingHasher{}
+	throttle := &fakeThrottler{attempts: 2, failures: map[string]int{}}
+	s := NewUserService(repo, hasher, nil, nil, nil, throttle, nil)
+	reset := func(token string) *shared.DomainError {
+		return s.ResetPassword(context.Background(), &dto.ResetPasswordRequest{
+			Token:    token,
+			Password: "new password",
+			Client:   dto.ClientInfo{IP: "192.0.2.1"},
+		})
+	}
+
+	for _, token := range []string{"guess-1", "guess-2"} {
+		if err := reset(token); err == nil || err.Code != "INVALID_RESET_TOKEN" {
+			t.Fatalf("reset with %q = %v, want INVALID_RESET_TOKEN", token, err)
+		}
+	}
+	if hasher.hashed != 0 {
+		t.Errorf("password hashed %d times for invalid tokens, want 0", hasher.hashed)
+	}
+
+	if err := reset("guess-3"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("third guess = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	// Locked out clients cannot use a valid token either
+	if err := reset("valid"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Errorf("reset after lockout = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+
+	throttle.failures = map[string]int{}
+	if err := reset("valid"); err != nil {
+		t.Fatalf("reset with a valid token: %v", err)
+	}
+	if hasher.hashed != 1 {
+		t.Errorf("password hashed %d times, want 1", hasher.hashed)
+	}
+}
+
+func TestRequestPasswordResetIsThrottled(t *testing.T) {
+	throttle := &fakeThrottler{attempts: 1, failures: map[string]int{}}
+	s := NewUserService(&fakeUserRepo{}, nil, nil, nil, nil, throttle, nil)
+	request := func(email string) *shared.DomainError {
+		return s.RequestPasswordReset(context.Background(), &dto.ForgotPasswordRequest{
+			Email:  email,
+			Client: dto.ClientInfo{IP: "192.0.2.1"},
+		})
+	}
+
+	if err := request("jane@example.com"); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if err := request("Jane@Example.com "); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("second request = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	// Reset requests must not lock the account out of signing in
+	if n := throttle.failures["jane@example.com"]; n != 0 {
+		t.Errorf("sign-in failures of the account = %d, want 0", n)
+	}
+}
+
+// changePasswordRepo records password changes.
+type changePasswordRepo struct {
+	fakeUserRepo
+	changed int
+}
+
+func (r *changePasswordRepo) ChangePassword(context.Context, uuid.UUID, string, audit.Actor) *shared.DomainError {
+	r.changed++
+	return nil
+}
+
+func TestChangePasswordIsThrottled(t *testing.T) {
+	u := &user.User{ID: uuid.New(), Email: "jane@example.com", Password: "hashed:secret"}
+	repo := &changePasswordRepo{fakeUserRepo: fakeUserRepo{users: map[uuid.UUID]*user.User{u.ID: u}}}
+	throttle := &fakeThrottler{attempts: 1, failures: map[string]int{}}
+	s := NewUserService(repo, &countingHasher{}, nil, nil, nil, throttle, nil)
+	change := func(current string) *shared.DomainError {
+		return s.ChangePassword(context.Background(), u.ID, &dto.ChangePasswordRequest{
+			CurrentPassword: current,
+			NewPassword:     "new password",
+			Client:          dto.ClientInfo{IP: "192.0.2.1"},
+		})
+	}
+
+	if err := change("guess-1"); err == nil || err.Code != "INVALID_CREDENTIALS" {
+		t.Fatalf("first guess = %v, want INVALID_CREDENTIALS", err)
+	}
+	if err := change("guess-2"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("second guess = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	// The guesses count against the account like failed sign-ins
+	if got := throttle.failures[u.Email]; got != 2 {
+		t.Errorf("sign-in failures of the account = %d, want 2", got)
+	}
+	if err := change("secret"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("change after lockout = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	if repo.changed != 0 {
+		t.Errorf("password changed %d times, want 0", repo.changed)
+	}
+}
+
+type recordingLogger struct {
+	logger.Logger
+	errors []string
+}
+
+func (l *recordingLogger) Error(msg string, _ ...zap.Field) {
+	l.errors = append(l.errors, msg)
+}
+
+// failingUserRepo fails every lookup with a database error.
+type failingUserRepo struct {
+	fakeUserRepo
+}
+
+func (failingUserRepo) GetUserByEmail(context.Context, string) (*user.User, *shared.DomainError) {
+	return nil, shared.NewDomainError("FETCH_FAILED", 500, "internal database error")
+}
+
+func TestRequestPasswordResetLogsFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		repo       user.UserRepository
+		wantLogged int
+	}{
+		{"unknown email", &fakeUserRepo{}, 0},
+		{"database error", &failingUserRepo{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			throttle := &fakeThrottler{attempts: 10, failures: map[string]int{}}
+			s := NewUserService(tt.repo, nil, nil, nil, nil, throttle, log)
+
+			if err := s.RequestPasswordReset(context.Background(), &dto.ForgotPasswordRequest{Email: "jane@example.com"}); err != nil {
+				t.Fatalf("RequestPasswordReset: %v", err)
+			}
+			if err := s.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait: %v", err)
+			}
+			if len(log.errors) != tt.wantLogged {
+				t.Errorf("logged %q, want %d errors", log.errors, tt.wantLogged)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+)
+
+const (
+	// sessionTouchInterval limits how often authenticated requests update
+	// the last-seen time of their session.
+	sessionTouchInterval = time.Minute
+	maxUserAgentLength   = 512
+)
+
+var errSessionRevoked = shared.NewDomainError("SESSION_REVOKED", 401, "session has been revoked or has expired")
+
+func (s *AuthServiceImpl) ListSessions(ctx context.Context, userID, currentID uuid.UUID) (*dto.SessionListResponse, *shared.DomainError) {
+	sessions, err := s.sessions.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]dto.SessionDetail, len(*sessions))
+	for i, session := range *sessions {
+		details[i] = dto.SessionDetail{
+			ID:         session.ID.String(),
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.ID == currentID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+	return &dto.SessionListResponse{Sessions: details}, nil
+}
+
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID, id uuid.UUID) *shared.DomainError {
+	return s.sessions.RevokeSession(ctx, userID, id)
+}
+
+func (s *AuthServiceImpl) RevokeOtherSessions(ctx context.Context, userID, currentID uuid.UUID) *shared.DomainError {
+	return s.sessions.RevokeOtherSessions(ctx, userID, currentID)
+}
+
+// checkSession rejects access tokens whose session was revoked, expired or
+// belongs to another user, and records activity on the session.
+func (s *AuthServiceImpl) checkSession(ctx context.Context, claims *auth.Claims) error {
+	id, parseErr := uuid.Parse(claims.SessionID)
+	if parseErr != nil {
+		return errSessionRevoked
+	}
+	session, err := s.sessions.GetSession(ctx, id)
+	if err != nil {
+		if err.StatusCode == 404 {
+			return errSessionRevoked
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	if !session.Active(now) || session.UserID.String() != claims.UserID {
+		return errSessionRevoked
+	}
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		_ = s.sessions.TouchSession(ctx, id, "")
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
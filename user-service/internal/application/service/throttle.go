@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+func errTooManyAttempts(wait time.Duration) *shared.DomainError {
+	return shared.NewDomainError("TOO_MANY_ATTEMPTS", 429, "too many failed attempts, try again later").WithRetryAfter(wait)
+}
+
+// throttled runs attempt unless ip or account is locked out and records the
+// outcome. Only 401s count as failed attempts; other errors such as a
+// disabled account say nothing about guessed credentials.
+func throttled[T any](t auth.LoginThrottler, ip, account string, attempt func() (T, *shared.DomainError)) (T, *shared.DomainError) {
+	var zero T
+	if wait := t.Check(ip, account); wait > 0 {
+		return zero, errTooManyAttempts(wait)
+	}
+
+	res, err := attempt()
+	if err != nil {
+		if err.StatusCode == 401 {
+			if wait := t.Failure(ip, account); wait > 0 {
+				return zero, errTooManyAttempts(wait)
+			}
+		}
+		return zero, err
+	}
+	t.Success(ip, account)
+	return res, nil
+}
+
+// countRequest counts a request against ip and account whether or not it
+// succeeds. It is used for requests that send an email, where every request
+// costs something, not only failed ones.
+func countRequest(t auth.LoginThrottler, ip, account string) *shared.DomainError {
+	if wait := t.Check(ip, account); wait > 0 {
+		return errTooManyAttempts(wait)
+	}
+	if wait := t.Failure(ip, account); wait > 0 {
+		return errTooManyAttempts(wait)
+	}
+	return nil
+}
+
+// verifyTwoFactorLogin checks the MFA token issued by the first login step
+// and the second factor, throttling code guesses per client IP and user ID.
+func verifyTwoFactorLogin(
+	ctx context.Context,
+	t auth.LoginThrottler,
+	users user.UserService,
+	verify func(token string) (*auth.Claims, error),
+	req *dto.TwoFactorLoginRequest,
+) (*user.User, *shared.DomainError) {
+	claims, verifyErr := verify(req.MFAToken)
+	if verifyErr != nil {
+		return nil, errInvalidMFAToken
+	}
+	id, parseErr := uuid.Parse(claims.UserID)
+	if parseErr != nil {
+		return nil, errInvalidMFAToken
+	}
+	return throttled(t, req.Client.IP, id.String(), func() (*user.User, *shared.DomainError) {
+		return users.VerifySecondFactor(ctx, id, req.Code)
+	})
+}
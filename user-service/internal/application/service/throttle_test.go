@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// fakeThrottler locks a key out once it failed more than attempts times.
+type fakeThrottler struct {
+	auth.LoginThrottler
+	attempts int
+	failures map[string]int
+}
+
+func (f *fakeThrottler) Check(ip, account string) time.Duration {
+	if f.failures[ip] > f.attempts || f.failures[account] > f.attempts {
+		return time.Minute
+	}
+	return 0
+}
+
+func (f *fakeThrottler) Failure(ip, account string) time.Duration {
+	f.failures[ip]++
+	if account != "" {
+		f.failures[account]++
+	}
+	return f.Check(ip, account)
+}
+
+func (f *fakeThrottler) Success(_, account string) {
+	delete(f.failures, account)
+}
+
+func TestThrottledCountsOnlyFailedCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *shared.DomainError
+		failures int
+	}{
+		{"success", nil, 0},
+		{"wrong password", shared.NewDomainError("INVALID_CREDENTIALS", 401, "invalid credentials"), 1},
+		{"disabled account", errAccountDisabled, 0},
+		{"database error", shared.NewDomainError("FETCH_FAILED", 500, "internal database error"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := &fakeThrottler{attempts: 10, failures: map[string]int{}}
+			_, err := throttled(th, "192.0.2.1", "jane@example.com", func() (struct{}, *shared.DomainError) {
+				return struct{}{}, tt.err
+			})
+			if err != tt.err {
+				t.Errorf("throttled = %v, want %v", err, tt.err)
+			}
+			if got := th.failures["192.0.2.1"]; got != tt.failures {
+				t.Errorf("failures = %d, want %d", got, tt.failures)
+			}
+		})
+	}
+}
+
+func TestThrottledRejectsLockedOutCallers(t *testing.T) {
+	th := &fakeThrottler{attempts: 1, failures: map[string]int{}}
+	wrongPassword := func() (struct{}, *shared.DomainError) {
+		return struct{}{}, shared.NewDomainError("INVALID_CREDENTIALS", 401, "invalid credentials")
+	}
+
+	if _, err := throttled(th, "192.0.2.1", "jane@example.com", wrongPassword); err.Code != "INVALID_CREDENTIALS" {
+		t.Fatalf("first failure = %v, want INVALID_CREDENTIALS", err)
+	}
+	// The failure that causes the lockout already reports it
+	if _, err := throttled(th, "192.0.2.1", "jane@example.com", wrongPassword); err.Code != "TOO_MANY_ATTEMPTS" || err.RetryAfter == 0 {
+		t.Fatalf("second failure = %+v, want TOO_MANY_ATTEMPTS with a retry delay", err)
+	}
+
+	called := false
+	_, err := throttled(th, "192.0.2.1", "jane@example.com", func() (struct{}, *shared.DomainError) {
+		called = true
+		return struct{}{}, nil
+	})
+	if err == nil || err.Code != "TOO_MANY_ATTEMPTS" || called {
+		t.Errorf("attempt while locked out = %v (attempted %v), want TOO_MANY_ATTEMPTS without an attempt", err, called)
+	}
+}
+
+// Requests that send an email count even when they succeed or fail for
+// reasons unrelated to credentials.
+func TestResendVerificationEmailCountsEveryRequest(t *testing.T) {
+	now := time.Now()
+	u := &user.User{ID: uuid.New(), Email: "jane@example.com", EmailVerifiedAt: &now}
+	throttle := &fakeThrottler{attempts: 1, failures: map[string]int{}}
+	s := NewUserService(&fakeUserRepo{users: map[uuid.UUID]*user.User{u.ID: u}}, nil, nil, nil, nil, throttle, nil)
+	client := dto.ClientInfo{IP: "192.0.2.1"}
+
+	if err := s.ResendVerificationEmail(context.Background(), u.ID, client); err == nil || err.Code != "EMAIL_ALREADY_VERIFIED" {
+		t.Fatalf("first request = %v, want EMAIL_ALREADY_VERIFIED", err)
+	}
+	if err := s.ResendVerificationEmail(context.Background(), u.ID, client); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("second request = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	if got := throttle.failures[emailVerificationAccount(u.ID)]; got != 2 {
+		t.Errorf("requests counted for the account = %d, want 2", got)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	errTwoFactorEnabled    = shared.NewDomainError("TWO_FACTOR_ALREADY_ENABLED", 409, "two-factor authentication is already enabled")
+	errTwoFactorNotEnabled = shared.NewDomainError("TWO_FACTOR_NOT_ENABLED", 400, "two-factor authentication is not enabled")
+	errTwoFactorNotEnroll  = shared.NewDomainError("TWO_FACTOR_NOT_ENROLLED", 400, "two-factor enrollment has not been started")
+	errInvalidTwoFactor    = shared.NewDomainError("INVALID_TWO_FACTOR_CODE", 401, "invalid two-factor code")
+)
+
+// EnrollTOTP starts TOTP enrollment by generating a new secret. Two-factor
+// authentication is only enabled once ConfirmTOTP receives a valid code.
+func (s *UserServiceImpl) EnrollTOTP(ctx context.Context, id uuid.UUID) (*dto.TOTPEnrollmentResponse, *shared.DomainError) {
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactorEnabled() {
+		return nil, errTwoFactorEnabled
+	}
+
+	secret, genErr := s.totp.GenerateSecret()
+	if genErr != nil {
+		return nil, shared.NewDomainError("TWO_FACTOR_SETUP_FAILED", 500, "failed to generate secret")
+	}
+	sealed, sealErr := s.totp.Seal(secret)
+	if sealErr != nil {
+		return nil, shared.NewDomainError("TWO_FACTOR_SETUP_FAILED", 500, "failed to encrypt secret")
+	}
+	if err := s.repo.SetTOTPSecret(ctx, id, sealed); err != nil {
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollmentResponse{
+		Secret: secret,
+		URI:    s.totp.ProvisioningURI(secret, u.Email),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the
+// authenticator works. Wrong codes are throttled per client IP and user ID,
+// like the second login step.
+func (s *UserServiceImpl) ConfirmTOTP(ctx context.Context, id uuid.UUID, req *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, *shared.DomainError) {
+	return throttled(s.throttle, req.Client.IP, id.String(), func() (*dto.RecoveryCodesResponse, *shared.DomainError) {
+		return s.confirmTOTP(ctx, id, req.Code)
+	})
+}
+
+func (s *UserServiceImpl) confirmTOTP(ctx context.Context, id uuid.UUID, code string) (*dto.RecoveryCodesResponse, *shared.DomainError) {
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactorEnabled() {
+		return nil, errTwoFactorEnabled
+	}
+	if u.TOTPSecret == "" {
+		return nil, errTwoFactorNotEnroll
+	}
+
+	if err := s.verifyTOTP(ctx, u, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, genErr := generateRecoveryCodes()
+	if genErr != nil {
+		return nil, genErr
+	}
+	if err := s.repo.EnableTOTP(ctx, id, hashes); err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *UserServiceImpl) DisableTOTP(ctx context.Context, id uuid.UUID, req *dto.TOTPChangeRequest) *shared.DomainError {
+	_, err := throttled(s.throttle, req.Client.IP, id.String(), func() (struct{}, *shared.DomainError) {
+		if err := s.reauthenticate(ctx, id, req); err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, s.repo.DisableTOTP(ctx, id)
+	})
+	return err
+}
+
+func (s *UserServiceImpl) RegenerateRecoveryCodes(ctx context.Context, id uuid.UUID, req *dto.TOTPChangeRequest) (*dto.RecoveryCodesResponse, *shared.DomainError) {
+	return throttled(s.throttle, req.Client.IP, id.String(), func() (*dto.RecoveryCodesResponse, *shared.DomainError) {
+		if err := s.reauthenticate(ctx, id, req); err != nil {
+			return nil, err
+		}
+
+		codes, hashes, err := generateRecoveryCodes()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.ReplaceRecoveryCodes(ctx, id, hashes); err != nil {
+			return nil, err
+		}
+		return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+	})
+}
+
+// reauthenticate checks the current password and then a second factor
+// before two-factor settings change, so that a stolen session alone cannot
+// turn two-factor authentication off. The password comes first so that a
+// wrong one does not use up a recovery code.
+func (s *UserServiceImpl) reauthenticate(ctx context.Context, id uuid.UUID, req *dto.TOTPChangeRequest) *shared.DomainError {
+	u, err := s.repo.GetUserByID(shared.WithPrimaryReads(ctx), id)
+	if err != nil {
+		return err
+	}
+	if ok, _ := s.hasher.Verify(req.Password, u.Password); !ok {
+		return shared.NewDomainError("INVALID_CREDENTIALS", 401, "password is incorrect")
+	}
+	_, err = s.VerifySecondFactor(ctx, id, req.Code)
+	return err
+}
+
+// VerifySecondFactor accepts either a current TOTP code or an unused
+// recovery code for a user with two-factor authentication enabled. Like the
+// rest of the two-factor flows it reads from the primary, since enrollment
+// state changes from one request to the next.
+func (s *UserServiceImpl) VerifySecondFactor(ctx context.Context, id uuid.UUID, code string) (*user.User, *shared.DomainError) {
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !u.TwoFactorEnabled() {
+		return nil, errTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if isDigits(code) {
+		if err := s.verifyTOTP(ctx, u, code); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	ok, err := s.repo.ConsumeRecoveryCode(ctx, id, hashRecoveryCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidTwoFactor
+	}
+	return u, nil
+}
+
+func (s *UserServiceImpl) verifyTOTP(ctx context.Context, u *user.User, code string) *shared.DomainError {
+	secret, openErr := s.totp.Open(u.TOTPSecret)
+	if openErr != nil {
+		return shared.NewDomainError("TWO_FACTOR_VERIFY_FAILED", 500, "failed to decrypt secret")
+	}
+
+	step, ok := s.totp.Validate(secret, code)
+	if !ok {
+		return errInvalidTwoFactor
+	}
+	consumed, err := s.repo.ConsumeTOTPStep(ctx, u.ID, step)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errInvalidTwoFactor
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns the plain codes shown to the user once and
+// the hashes that are persisted.
+func generateRecoveryCodes() ([]string, []string, *shared.DomainError) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	buf := make([]byte, 7)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, shared.NewDomainError("TWO_FACTOR_SETUP_FAILED", 500, "failed to generate recovery codes")
+		}
+		raw := strings.ToLower(enc.EncodeToString(buf))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normalizes the code so that case and separators typed by
+// the user do not matter.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
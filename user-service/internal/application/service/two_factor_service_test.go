@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// twoFactorRepo holds unused recovery code hashes in memory.
+type twoFactorRepo struct {
+	fakeUserRepo
+	codes    map[string]bool
+	disabled bool
+}
+
+func (r *twoFactorRepo) ConsumeRecoveryCode(_ context.Context, _ uuid.UUID, codeHash string) (bool, *shared.DomainError) {
+	ok := r.codes[codeHash]
+	delete(r.codes, codeHash)
+	return ok, nil
+}
+
+func (r *twoFactorRepo) DisableTOTP(context.Context, uuid.UUID) *shared.DomainError {
+	r.disabled = true
+	return nil
+}
+
+func TestDisableTOTPRequiresPasswordAndSecondFactor(t *testing.T) {
+	now := time.Now()
+	u := &user.User{ID: uuid.New(), Password: "hashed:secret", TOTPEnabledAt: &now}
+	repo := &twoFactorRepo{
+		fakeUserRepo: fakeUserRepo{users: map[uuid.UUID]*user.User{u.ID: u}},
+		codes:        map[string]bool{hashRecoveryCode("abcde-fghij"): true},
+	}
+	throttle := &fakeThrottler{attempts: 2, failures: map[string]int{}}
+	s := NewUserService(repo, &countingHasher{}, nil, nil, nil, throttle, nil)
+	disable := func(password, code string) *shared.DomainError {
+		return s.DisableTOTP(context.Background(), u.ID, &dto.TOTPChangeRequest{
+			Password: password,
+			Code:     code,
+			Client:   dto.ClientInfo{IP: "192.0.2.1"},
+		})
+	}
+
+	if err := disable("wrong", "abcde-fghij"); err == nil || err.Code != "INVALID_CREDENTIALS" {
+		t.Fatalf("disable with a wrong password = %v, want INVALID_CREDENTIALS", err)
+	}
+	if !repo.codes[hashRecoveryCode("abcde-fghij")] {
+		t.Error("a wrong password used up the recovery code")
+	}
+	if err := disable("secret", "zzzzz-zzzzz"); err == nil || err.Code != "INVALID_TWO_FACTOR_CODE" {
+		t.Fatalf("disable with a wrong code = %v, want INVALID_TWO_FACTOR_CODE", err)
+	}
+	if got := throttle.failures[u.ID.String()]; got != 2 {
+		t.Errorf("failures for the user = %d, want 2", got)
+	}
+	if err := disable("secret", "zzzzz-zzzzz"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("third wrong code = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	// Locked out clients cannot use a valid code either
+	if err := disable("secret", "abcde-fghij"); err == nil || err.Code != "TOO_MANY_ATTEMPTS" {
+		t.Fatalf("disable after lockout = %v, want TOO_MANY_ATTEMPTS", err)
+	}
+
+	throttle.failures = map[string]int{}
+	if err := disable("secret", "abcde-fghij"); err != nil {
+		t.Fatalf("disable with valid credentials: %v", err)
+	}
+	if !repo.disabled {
+		t.Error("two-factor authentication was not disabled")
+	}
+}
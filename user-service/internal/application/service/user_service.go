@@ -1,56 +1,243 @@
 package service
 
 import (
+	"context"
+	"strings"
+	"sync"
+
 	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/google/uuid"
 )
 
 type UserServiceImpl struct {
-	repo user.UserRepository
+	repo     user.UserRepository
+	hasher   user.PasswordHasher
+	totp     user.TOTPProvider
+	tokens   user.VerificationTokens
+	notifier user.Notifier
+	throttle auth.LoginThrottler
+	log      logger.Logger
+
+	// background tracks work that outlives its request, such as password
+	// reset mails, so that shutdown can wait for it.
+	background sync.WaitGroup
 }
 
-func NewUserService(repo user.UserRepository) *UserServiceImpl {
+func NewUserService(
+	repo user.UserRepository,
+	hasher user.PasswordHasher,
+	totp user.TOTPProvider,
+	tokens user.VerificationTokens,
+	notifier user.Notifier,
+	throttle auth.LoginThrottler,
+	log logger.Logger,
+) *UserServiceImpl {
 	return &UserServiceImpl{
-		repo: repo,
+		repo:     repo,
+		hasher:   hasher,
+		totp:     totp,
+		tokens:   tokens,
+		notifier: notifier,
+		throttle: throttle,
+		log:      log,
 	}
 }
 
 // Compile-time interface check
 var _ user.UserService = (*UserServiceImpl)(nil)
 
+// Wait blocks until background work has finished or ctx is done.
+func (s *UserServiceImpl) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var errAccountDisabled = shared.NewDomainError("ACCOUNT_DISABLED", 403, "account has been disabled")
+
 // Implement service methods here
-func (s *UserServiceImpl) CreateUser(req *dto.CreateUserRequest) (*dto.CreateUserResponse, *shared.DomainError) {
-	// Implementation goes here
+func (s *UserServiceImpl) CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.CreateUserResponse, *shared.DomainError) {
+	hash, hashErr := s.hasher.Hash(req.Password)
+	if hashErr != nil {
+		return nil, shared.NewDomainError("HASH_FAILED", 500, "failed to hash password")
+	}
 	user := &user.User{
 		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password, // In real scenarios, ensure to hash the password
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+		Password: hash,
 	}
-	id, err := s.repo.CreateUser(user)
+	id, err := s.repo.CreateUser(ctx, user, auditActor(uuid.Nil, req.Client))
 	if err != nil {
 		return nil, err
 	}
+	// Registration succeeds even if the mail cannot be sent; the user can
+	// request another one.
+	_ = s.sendVerificationEmail(user)
 	return &dto.CreateUserResponse{ID: id.String()}, nil
 }
 
-func (s *UserServiceImpl) GetUser() (*dto.GetUserResponse, *shared.DomainError) {
-	// Implementation goes here
-	users, err := s.repo.GetUser()
+func (s *UserServiceImpl) GetUserByID(ctx context.Context, id uuid.UUID) (*dto.UserDetail, *shared.DomainError) {
+	u, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUserDetail(u), nil
+}
+
+func (s *UserServiceImpl) UpdateProfile(ctx context.Context, id uuid.UUID, req *dto.UpdateProfileRequest) (*dto.UserDetail, *shared.DomainError) {
+	u := &user.User{ID: id, Name: strings.TrimSpace(req.Name)}
+	if err := s.repo.UpdateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	return toUserDetail(u), nil
+}
+
+// ChangeEmail changes the user's email after re-checking the current password.
+// Like ResendVerificationEmail it counts every request, since each change
+// mails the new address and each one is a guess at the password.
+func (s *UserServiceImpl) ChangeEmail(ctx context.Context, id uuid.UUID, req *dto.ChangeEmailRequest) (*dto.UserDetail, *shared.DomainError) {
+	if err := countRequest(s.throttle, req.Client.IP, emailVerificationAccount(id)); err != nil {
+		return nil, err
+	}
+	ctx = shared.WithPrimaryReads(ctx)
+	u, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if users == nil || len(*users) == 0 {
-		return &dto.GetUserResponse{Users: []dto.UserDetail{}}, nil
+	if ok, _ := s.hasher.Verify(req.Password, u.Password); !ok {
+		return nil, shared.NewDomainError("INVALID_CREDENTIALS", 401, "password is incorrect")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == u.Email {
+		return toUserDetail(u), nil
+	}
+	if err := s.repo.UpdateEmail(ctx, id, email, auditActor(id, req.Client)); err != nil {
+		return nil, err
+	}
+	u, err = s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	_ = s.sendVerificationEmail(u)
+	return toUserDetail(u), nil
+}
+
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, id uuid.UUID, client dto.ClientInfo) *shared.DomainError {
+	return s.repo.DeleteUser(ctx, id, auditActor(id, client))
+}
 
-	userDetails := make([]dto.UserDetail, len(*users))
-	for i, u := range *users {
-		userDetails[i] = dto.UserDetail{
-			ID:    u.ID.String(),
-			Name:  u.Name,
-			Email: u.Email,
+// Authenticate verifies the credentials and returns the matching user. When the
+// stored hash was produced with outdated parameters it is transparently
+// re-hashed with the current configuration.
+func (s *UserServiceImpl) Authenticate(ctx context.Context, email, password string) (*user.User, *shared.DomainError) {
+	invalid := shared.NewDomainError("INVALID_CREDENTIALS", 401, "invalid email or password")
+	// A replica could still hold a password that was just changed
+	ctx = shared.WithPrimaryReads(ctx)
+
+	u, err := s.repo.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if err.StatusCode == 404 {
+			// Burn roughly the same time as a real verification so that
+			// response timing does not reveal which emails are registered.
+			_, _ = s.hasher.Hash(password)
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	ok, verifyErr := s.hasher.Verify(password, u.Password)
+	if verifyErr != nil || !ok {
+		return nil, invalid
+	}
+	if u.Disabled() {
+		return nil, errAccountDisabled
+	}
+
+	if s.hasher.NeedsRehash(u.Password) {
+		if hash, hashErr := s.hasher.Hash(password); hashErr == nil {
+			if err := s.repo.UpdatePassword(ctx, u.ID, hash); err == nil {
+				u.Password = hash
+			}
 		}
 	}
-	return &dto.GetUserResponse{Users: userDetails}, nil
+	return u, nil
+}
+
+// listUsers resolves the listing request into a filter and returns one page
+// of users together with the cursor metadata.
+func listUsers(ctx context.Context, repo user.UserRepository, req *dto.ListUsersRequest) ([]user.User, *dto.CursorMeta, *shared.DomainError) {
+	filter := &user.UserFilter{
+		EmailPrefix: strings.ToLower(strings.TrimSpace(req.EmailPrefix)),
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		SortBy:      req.Sort,
+		Limit:       pageSize(req.Limit),
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = user.SortByCreatedAt
+	}
+	switch strings.ToLower(req.Order) {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return nil, nil, shared.NewDomainError("INVALID_ORDER", 400, "order must be asc or desc")
+	}
+	if req.Cursor != "" {
+		after, ok := decodeUserCursor(req.Cursor, filter.SortBy, filter.Descending)
+		if !ok {
+			return nil, nil, shared.NewDomainError("INVALID_CURSOR", 400, "cursor is invalid")
+		}
+		filter.After = after
+	}
+
+	users, err := repo.GetUser(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := *users
+	meta := &dto.CursorMeta{Limit: filter.Limit}
+	if len(page) > filter.Limit {
+		page = page[:filter.Limit]
+		meta.HasMore = true
+		meta.NextCursor = encodeUserCursor(user.CursorFor(&page[len(page)-1], filter.SortBy), filter.SortBy, filter.Descending)
+	}
+	return page, meta, nil
+}
+
+func toUserDetail(u *user.User) *dto.UserDetail {
+	return &dto.UserDetail{
+		ID:               u.ID.String(),
+		Name:             u.Name,
+		Email:            u.Email,
+		EmailVerified:    u.EmailVerified(),
+		TwoFactorEnabled: u.TwoFactorEnabled(),
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
+	}
+}
+
+// auditActor describes who makes a change for the audit log. A uuid.Nil actorID
+// attributes the change to the affected user.
+func auditActor(actorID uuid.UUID, client dto.ClientInfo) audit.Actor {
+	return audit.Actor{
+		UserID:    actorID,
+		IP:        client.IP,
+		RequestID: client.RequestID,
+	}
 }
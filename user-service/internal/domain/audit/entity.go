@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionUserCreated     = "user.created"
+	ActionEmailChanged    = "user.email_changed"
+	ActionPasswordChanged = "user.password_changed"
+	ActionPasswordReset   = "user.password_reset"
+	ActionRolesChanged    = "user.roles_changed"
+	ActionUserDisabled    = "user.disabled"
+	ActionUserEnabled     = "user.enabled"
+	ActionUserDeleted     = "user.deleted"
+	ActionIdentityLinked  = "user.identity_linked"
+	ActionBalanceCredited = "balance.credited"
+	ActionBalanceDebited  = "balance.debited"
+)
+
+// Entry is an append-only record of a change to a user account. Entries
+// are kept after the user is deleted, so UserID is not a foreign key.
+type Entry struct {
+	bun.BaseModel `bun:"table:audit_log,alias:al"`
+	ID            uuid.UUID      `bun:",pk,nullzero"`
+	UserID        uuid.UUID      `bun:",notnull"`
+	ActorID       uuid.UUID      `bun:",nullzero"`
+	Action        string         `bun:",notnull"`
+	IP            string         `bun:"ip,nullzero"`
+	RequestID     string         `bun:",nullzero"`
+	Details       map[string]any `bun:"type:jsonb,nullzero"`
+	CreatedAt     time.Time      `bun:",nullzero,default:current_timestamp"`
+}
+
+// Actor identifies who made a change and the request it was made in. A
+// zero UserID means the user changed their own account, e.g. while
+// registering or resetting a forgotten password.
+type Actor struct {
+	UserID    uuid.UUID
+	IP        string
+	RequestID string
+}
+
+// Entry returns the audit entry for action on userID made by a.
+func (a Actor) Entry(userID uuid.UUID, action string, details map[string]any) *Entry {
+	actorID := a.UserID
+	if actorID == uuid.Nil {
+		actorID = userID
+	}
+	return &Entry{
+		UserID:    userID,
+		ActorID:   actorID,
+		Action:    action,
+		IP:        a.IP,
+		RequestID: a.RequestID,
+		Details:   details,
+	}
+}
+
+// Filter selects a page of audit entries, newest first.
+type Filter struct {
+	UserID  *uuid.UUID
+	ActorID *uuid.UUID
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	After   *Cursor
+}
+
+// Cursor is the keyset position after which the next page starts.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// AuditRepository defines the methods that any
+type AuditRepository interface {
+	// ListEntries returns filter.Limit+1 entries so the caller can tell
+	// whether another page exists. Entries are written by the repositories
+	// that make the audited change, in the same transaction.
+	ListEntries(ctx context.Context, filter *Filter) (*[]Entry, *shared.DomainError)
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// AccessToken is a signed access token together with its expiry.
+type AccessToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Claims are the verified claims carried by an access token.
+type Claims struct {
+	TokenID       string
+	SessionID     string
+	UserID        string
+	Email         string
+	EmailVerified bool
+	Permissions   []string
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+}
+
+// RefreshToken is a persisted, single-use refresh token. Tokens issued from
+// the same login share a FamilyID so that a replayed token can revoke every
+// descendant at once.
+type RefreshToken struct {
+	bun.BaseModel `bun:"table:refresh_tokens,alias:rt"`
+	ID            uuid.UUID  `bun:",pk,nullzero"`
+	UserID        uuid.UUID  `bun:",notnull"`
+	FamilyID      uuid.UUID  `bun:",notnull"`
+	TokenHash     string     `bun:",notnull"`
+	ExpiresAt     time.Time  `bun:",notnull"`
+	RotatedAt     *time.Time `bun:",nullzero"`
+	RevokedAt     *time.Time `bun:",nullzero"`
+	CreatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+}
+
+// OpaqueToken is a freshly generated opaque token. Only Hash is persisted.
+type OpaqueToken struct {
+	Token     string
+	Hash      string
+	ExpiresAt time.Time
+}
+
+// TokenManager issues and verifies signed access tokens and opaque refresh tokens.
+type TokenManager interface {
+	// IssueAccessToken issues an access token bound to the given session so
+	// that revoking the session also rejects the token.
+	IssueAccessToken(u *user.User, sessionID uuid.UUID) (*AccessToken, error)
+	// VerifyAccessToken only accepts tokens with the "access" audience. Other
+	// services verifying access tokens against the JWKS must check it too.
+	VerifyAccessToken(token string) (*Claims, error)
+	// IssueMFAToken issues a short-lived challenge proving the first login
+	// factor succeeded. It cannot be used as an access token.
+	IssueMFAToken(u *user.User) (*AccessToken, error)
+	VerifyMFAToken(token string) (*Claims, error)
+	IssueRefreshToken() (*OpaqueToken, error)
+	HashToken(token string) string
+	JWKS() *dto.JWKSResponse
+}
+
+// AdminTokenManager issues and verifies admin access tokens. They are signed
+// with the admin key pair and carry the admin's permissions, so they are
+// never accepted as user access tokens and vice versa.
+type AdminTokenManager interface {
+	IssueAdminToken(u *user.User, permissions []string) (*AccessToken, error)
+	VerifyAdminToken(token string) (*Claims, error)
+	IssueMFAToken(u *user.User) (*AccessToken, error)
+	VerifyMFAToken(token string) (*Claims, error)
+}
+
+// RefreshTokenRepository defines the methods that any
+type RefreshTokenRepository interface {
+	// RotateRefreshToken marks the token identified by hash as used, stores
+	// next in the same family and records the activity and client ip on the
+	// session. Presenting an already rotated token revokes the whole family
+	// and returns REFRESH_TOKEN_REUSED.
+	RotateRefreshToken(ctx context.Context, hash string, next *RefreshToken, ip string) *shared.DomainError
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) *shared.DomainError
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) *shared.DomainError
+}
+
+// AuthService defines the methods that any
+type AuthService interface {
+	Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, *shared.DomainError)
+	LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.TokenResponse, *shared.DomainError)
+	Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.TokenResponse, *shared.DomainError)
+	StartOAuth(ctx context.Context, provider string) (*dto.OAuthStartResponse, *shared.DomainError)
+	// StartOAuthLink starts the same flow for a signed-in user; the callback
+	// then links the provider account to userID instead of signing in.
+	StartOAuthLink(ctx context.Context, userID uuid.UUID, provider string) (*dto.OAuthStartResponse, *shared.DomainError)
+	CompleteOAuth(ctx context.Context, provider, state, code string, client dto.ClientInfo) (*dto.LoginResponse, *shared.DomainError)
+	JWKS() *dto.JWKSResponse
+	ListSessions(ctx context.Context, userID, currentID uuid.UUID) (*dto.SessionListResponse, *shared.DomainError)
+	RevokeSession(ctx context.Context, userID, id uuid.UUID) *shared.DomainError
+	// RevokeOtherSessions signs the user out everywhere except currentID.
+	RevokeOtherSessions(ctx context.Context, userID, currentID uuid.UUID) *shared.DomainError
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Identity links a user to an account at an external OAuth provider.
+type Identity struct {
+	bun.BaseModel `bun:"table:user_identities,alias:ui"`
+	ID            uuid.UUID `bun:",pk,nullzero"`
+	UserID        uuid.UUID `bun:",notnull"`
+	Provider      string    `bun:",notnull"`
+	Subject       string    `bun:",notnull"`
+	Email         string    `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+}
+
+// OAuthState is a pending authorization request. It binds the state
+// parameter to the PKCE verifier and the OpenID Connect nonce and is
+// consumed by the callback. UserID is set when a signed-in user links the
+// provider to their account instead of signing in.
+type OAuthState struct {
+	bun.BaseModel `bun:"table:oauth_states,alias:os"`
+	StateHash     string    `bun:",pk"`
+	Provider      string    `bun:",notnull"`
+	CodeVerifier  string    `bun:",notnull"`
+	Nonce         string    `bun:",notnull"`
+	ExpiresAt     time.Time `bun:",notnull"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+
+	UserID uuid.UUID `bun:",nullzero"`
+}
+
+// ExternalProfile is the identity returned by a provider after the code exchange.
+type ExternalProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OAuthClient performs the authorization-code flow with PKCE against one provider.
+type OAuthClient interface {
+	AuthCodeURL(state, verifier, nonce string) string
+	// Exchange redeems the code and returns the user's profile. A profile read
+	// from an ID token is only returned if the token is signed by the
+	// provider, issued for this client and carries nonce.
+	Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalProfile, error)
+}
+
+// OAuthProviders looks up configured providers by name.
+type OAuthProviders interface {
+	Provider(name string) (OAuthClient, bool)
+}
+
+// OAuthRepository defines the methods that any
+type OAuthRepository interface {
+	CreateState(ctx context.Context, state *OAuthState) *shared.DomainError
+	// ConsumeState deletes and returns the state so it cannot be replayed.
+	ConsumeState(ctx context.Context, stateHash string) (*OAuthState, *shared.DomainError)
+	GetIdentity(ctx context.Context, provider, subject string) (*Identity, *shared.DomainError)
+	// CreateUserWithIdentity creates a user for a first-time social login and
+	// links the identity in the same transaction.
+	CreateUserWithIdentity(ctx context.Context, u *user.User, identity *Identity, actor audit.Actor) *shared.DomainError
+	// LinkIdentity links the identity to an existing user.
+	LinkIdentity(ctx context.Context, identity *Identity, actor audit.Actor) *shared.DomainError
+}
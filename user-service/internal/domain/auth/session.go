@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Session is a signed-in device. Every login starts one session and its ID
+// is the FamilyID of the refresh tokens rotated from that login.
+type Session struct {
+	bun.BaseModel `bun:"table:sessions,alias:s"`
+	ID            uuid.UUID  `bun:",pk,nullzero"`
+	UserID        uuid.UUID  `bun:",notnull"`
+	UserAgent     string     `bun:",nullzero"`
+	IP            string     `bun:"ip,nullzero"`
+	ExpiresAt     time.Time  `bun:",notnull"`
+	LastSeenAt    time.Time  `bun:",nullzero,default:current_timestamp"`
+	RevokedAt     *time.Time `bun:",nullzero"`
+	CreatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+}
+
+// Active reports whether the session can still be used at now.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionRepository defines the methods that any
+type SessionRepository interface {
+	// CreateSession stores the session together with its first refresh token.
+	CreateSession(ctx context.Context, session *Session, token *RefreshToken) *shared.DomainError
+	GetSession(ctx context.Context, id uuid.UUID) (*Session, *shared.DomainError)
+	// TouchSession records activity on an active session. An empty ip keeps
+	// the last known address.
+	TouchSession(ctx context.Context, id uuid.UUID, ip string) *shared.DomainError
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) (*[]Session, *shared.DomainError)
+	// RevokeSession revokes one of the user's sessions and its refresh tokens.
+	RevokeSession(ctx context.Context, userID, id uuid.UUID) *shared.DomainError
+	// RevokeOtherSessions revokes every session of the user except keepID.
+	RevokeOtherSessions(ctx context.Context, userID, keepID uuid.UUID) *shared.DomainError
+}
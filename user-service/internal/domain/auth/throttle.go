@@ -0,0 +1,16 @@
+package auth
+
+import "time"
+
+// LoginThrottler slows down credential guessing. Failures are counted per
+// client IP and per account; once a key runs out of free attempts it is
+// locked out with exponential backoff.
+type LoginThrottler interface {
+	// Check returns how long the caller has to wait before trying again, or
+	// zero when neither the IP nor the account is locked out.
+	Check(ip, account string) time.Duration
+	// Failure records a failed attempt and returns the lockout it caused, if any.
+	Failure(ip, account string) time.Duration
+	// Success forgets the account's failures after a successful sign-in.
+	Success(ip, account string)
+}
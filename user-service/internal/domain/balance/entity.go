@@ -0,0 +1,91 @@
+package balance
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Transaction types recorded in the ledger.
+const (
+	TypeCredit = "credit"
+	TypeDebit  = "debit"
+)
+
+// ExternalAccount is the system account that is the counterparty of money
+// entering or leaving user balances.
+const ExternalAccount = "external"
+
+// Account is a ledger account. User accounts keep a running balance in minor
+// units that can never go negative. System accounts are identified by a code;
+// their balance is the sum of their entries so that concurrent postings do
+// not contend on a single row.
+type Account struct {
+	bun.BaseModel `bun:"table:balance,alias:b"`
+	ID            uuid.UUID  `bun:",pk,nullzero"`
+	UserID        *uuid.UUID `bun:",nullzero"`
+	SystemCode    string     `bun:",nullzero"`
+	Amount        int64      `bun:",notnull"`
+	CreatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+	UpdatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+}
+
+// Transaction groups the entries of one balanced ledger posting.
+type Transaction struct {
+	bun.BaseModel `bun:"table:ledger_transactions,alias:lt"`
+	ID            uuid.UUID `bun:",pk,nullzero"`
+	Type          string    `bun:",notnull"`
+	Reference     string    `bun:",nullzero"`
+	Description   string    `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+}
+
+// Entry is one side of a transaction. Entries of a transaction sum to zero.
+type Entry struct {
+	bun.BaseModel `bun:"table:ledger_entries,alias:le"`
+	ID            uuid.UUID    `bun:",pk,nullzero"`
+	TransactionID uuid.UUID    `bun:",notnull"`
+	AccountID     uuid.UUID    `bun:",notnull"`
+	Amount        int64        `bun:",notnull"`
+	BalanceAfter  *int64       `bun:",nullzero"`
+	CreatedAt     time.Time    `bun:",nullzero,default:current_timestamp"`
+	Transaction   *Transaction `bun:"rel:belongs-to,join:transaction_id=id"`
+}
+
+// EntryFilter selects a page of a user's entries, newest first.
+type EntryFilter struct {
+	Limit int
+	After *EntryCursor
+}
+
+// EntryCursor is the keyset position after which the next page starts.
+type EntryCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// BalanceRepository defines the methods that any
+type BalanceRepository interface {
+	// GetAccount returns the user's account, or an empty one if the user has
+	// never been credited.
+	GetAccount(ctx context.Context, userID uuid.UUID) (*Account, *shared.DomainError)
+	// Post applies amount (negative for debits) to the user's account and the
+	// opposite amount to the external account in one serializable transaction,
+	// and records actor's change in the audit log.
+	Post(ctx context.Context, userID uuid.UUID, amount int64, txn *Transaction, actor audit.Actor) (*Entry, *shared.DomainError)
+	ListEntries(ctx context.Context, userID uuid.UUID, filter *EntryFilter) (*[]Entry, *shared.DomainError)
+}
+
+// BalanceService defines the methods that any
+type BalanceService interface {
+	GetBalance(ctx context.Context, userID uuid.UUID) (*dto.BalanceResponse, *shared.DomainError)
+	ListTransactions(ctx context.Context, userID uuid.UUID, req *dto.ListTransactionsRequest) (*dto.TransactionHistoryResponse, *dto.CursorMeta, *shared.DomainError)
+	// Credit and Debit adjust the user's balance on behalf of the admin actorID.
+	Credit(ctx context.Context, actorID, userID uuid.UUID, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError)
+	Debit(ctx context.Context, actorID, userID uuid.UUID, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError)
+}
@@ -0,0 +1,84 @@
+package rbac
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Permissions checked by the admin API. Each is seeded by the migration that
+// introduces it.
+const (
+	PermUsersRead     = "users:read"
+	PermUsersDisable  = "users:disable"
+	PermRolesRead     = "roles:read"
+	PermRolesAssign   = "roles:assign"
+	PermAuditRead     = "audit:read"
+	PermBalanceAdjust = "balance:adjust"
+)
+
+// Role is a named set of permissions that can be assigned to users.
+type Role struct {
+	bun.BaseModel `bun:"table:roles,alias:r"`
+	ID            uuid.UUID `bun:",pk,nullzero"`
+	Name          string    `bun:",notnull"`
+	Description   string    `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+	// Permissions holds the names of the role's permissions when loaded
+	// through ListRoles.
+	Permissions []string `bun:",array,scanonly"`
+}
+
+// Permission is a single capability checked by the admin API.
+type Permission struct {
+	bun.BaseModel `bun:"table:permissions,alias:p"`
+	ID            uuid.UUID `bun:",pk,nullzero"`
+	Name          string    `bun:",notnull"`
+	Description   string    `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+}
+
+// UserRole assigns a role to a user.
+type UserRole struct {
+	bun.BaseModel `bun:"table:user_roles,alias:ur"`
+	UserID        uuid.UUID `bun:",pk"`
+	RoleID        uuid.UUID `bun:",pk"`
+	CreatedAt     time.Time `bun:",nullzero,default:current_timestamp"`
+}
+
+// RoleRepository defines the methods that any
+type RoleRepository interface {
+	ListRoles(ctx context.Context) (*[]Role, *shared.DomainError)
+	// GetUserPermissions returns the distinct permissions granted to the user
+	// through all of their roles.
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, *shared.DomainError)
+	// GetUserRoles returns the role names of each given user.
+	GetUserRoles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, *shared.DomainError)
+	// SetUserRoles replaces the user's roles. Unknown role names are rejected
+	// with UNKNOWN_ROLE.
+	SetUserRoles(ctx context.Context, userID uuid.UUID, roles []string, actor audit.Actor) *shared.DomainError
+}
+
+// AdminService defines the methods that any
+type AdminService interface {
+	Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, *shared.DomainError)
+	LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.AdminTokenResponse, *shared.DomainError)
+	ListUsers(ctx context.Context, req *dto.ListUsersRequest) (*dto.AdminUserListResponse, *dto.CursorMeta, *shared.DomainError)
+	GetUser(ctx context.Context, id uuid.UUID) (*dto.AdminUserDetail, *shared.DomainError)
+	GetUserByEmail(ctx context.Context, email string) (*dto.AdminUserDetail, *shared.DomainError)
+	// DisableUser blocks the user from signing in and revokes their sessions.
+	// actorID is the calling admin, who cannot disable themselves.
+	DisableUser(ctx context.Context, actorID, id uuid.UUID, client dto.ClientInfo) (*dto.AdminUserDetail, *shared.DomainError)
+	EnableUser(ctx context.Context, actorID, id uuid.UUID, client dto.ClientInfo) (*dto.AdminUserDetail, *shared.DomainError)
+	// SetUserRoles replaces the user's roles. actorID is the calling admin,
+	// who cannot change their own roles.
+	SetUserRoles(ctx context.Context, actorID, id uuid.UUID, req *dto.SetUserRolesRequest) (*dto.AdminUserDetail, *shared.DomainError)
+	ListRoles(ctx context.Context) (*dto.RoleListResponse, *shared.DomainError)
+	// ListAuditLog returns a page of audit entries, newest first.
+	ListAuditLog(ctx context.Context, req *dto.ListAuditLogRequest) (*dto.AuditLogResponse, *dto.CursorMeta, *shared.DomainError)
+}
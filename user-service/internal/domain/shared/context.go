@@ -0,0 +1,18 @@
+package shared
+
+import "context"
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads marks ctx so that repositories read from the primary
+// database, bypassing read replicas and caches. Use it for reads that must observe the
+// caller's own writes or that back a security decision.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether ctx was marked by WithPrimaryReads.
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
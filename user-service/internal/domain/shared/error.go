@@ -1,9 +1,16 @@
 package shared
 
+import "time"
+
 type DomainError struct {
 	Code       string `json:"code"`        // e.g. "USER_NOT_FOUND"
 	StatusCode int    `json:"status_code"` // e.g. 404
 	Message    string `json:"message"`     // e.g. "User not found"
+	// Cause is the underlying error. It is kept for logging and never
+	// serialized, so driver messages do not leak to clients.
+	Cause error `json:"-"`
+	// RetryAfter tells clients how long to wait before retrying, if set.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interface.
@@ -11,6 +18,23 @@ func (e *DomainError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error, if any.
+func (e *DomainError) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause records the underlying error and returns e.
+func (e *DomainError) WithCause(err error) *DomainError {
+	e.Cause = err
+	return e
+}
+
+// WithRetryAfter records how long clients should wait and returns e.
+func (e *DomainError) WithRetryAfter(d time.Duration) *DomainError {
+	e.RetryAfter = d
+	return e
+}
+
 // NewDomainError creates a new DomainError with code, status, and message.
 func NewDomainError(code string, status int, message string) *DomainError {
 	return &DomainError{
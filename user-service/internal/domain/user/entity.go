@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
@@ -12,27 +13,117 @@ import (
 
 // User represents a user entity in the system.
 type User struct {
-	bun.BaseModel `bun:"table:users,alias:u"`
-	ID            uuid.UUID `json:"id" bun:",nullzero"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Password      string    `json:"-" bun:"password_hash"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	bun.BaseModel   `bun:"table:users,alias:u"`
+	ID              uuid.UUID  `json:"id" bun:",pk,nullzero"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"-" bun:"email_verified_at,nullzero"`
+	Password        string     `json:"-" bun:"password_hash"`
+	TOTPSecret      string     `json:"-" bun:"totp_secret,nullzero"`
+	TOTPEnabledAt   *time.Time `json:"-" bun:"totp_enabled_at,nullzero"`
+	TOTPLastStep    int64      `json:"-" bun:"totp_last_step,nullzero"`
+	DisabledAt      *time.Time `json:"-" bun:"disabled_at,nullzero"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 var _ bun.BeforeAppendModelHook = (*User)(nil)
 
 // UserRepository defines the methods that any
+//
+// The lookups may be served by a read replica or a cache that lag behind the
+// primary; mark ctx with shared.WithPrimaryReads to observe earlier writes.
 type UserRepository interface {
-	CreateUser(user *User) (uuid.UUID, *shared.DomainError)
-	GetUser() (*[]User, *shared.DomainError)
+	CreateUser(ctx context.Context, user *User, actor audit.Actor) (uuid.UUID, *shared.DomainError)
+	GetUser(ctx context.Context, filter *UserFilter) (*[]User, *shared.DomainError)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*User, *shared.DomainError)
+	GetUserByEmail(ctx context.Context, email string) (*User, *shared.DomainError)
+	UpdateUser(ctx context.Context, user *User) *shared.DomainError
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string, actor audit.Actor) *shared.DomainError
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) *shared.DomainError
+	// ChangePassword stores a new password hash and revokes every session.
+	ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, actor audit.Actor) *shared.DomainError
+	// CreatePasswordResetToken stores a reset token and invalidates any
+	// earlier unused token of the same user.
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) *shared.DomainError
+	// CheckPasswordResetToken reports whether an unused, unexpired token
+	// with the hash exists, without consuming it.
+	CheckPasswordResetToken(ctx context.Context, tokenHash string) *shared.DomainError
+	// ResetPassword consumes an unused, unexpired token, stores the new
+	// password hash and revokes every session of the token's user, whose ID
+	// it returns.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string, actor audit.Actor) (uuid.UUID, *shared.DomainError)
+	// MarkEmailVerified verifies the user's email only if it still equals email.
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) *shared.DomainError
+	// DeleteUser refuses users whose balance account has ledger entries;
+	// those can only be disabled.
+	DeleteUser(ctx context.Context, id uuid.UUID, actor audit.Actor) *shared.DomainError
+	// SetDisabled disables or re-enables the user. Disabling also revokes
+	// every session.
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool, actor audit.Actor) *shared.DomainError
+	SetTOTPSecret(ctx context.Context, id uuid.UUID, sealedSecret string) *shared.DomainError
+	EnableTOTP(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) *shared.DomainError
+	DisableTOTP(ctx context.Context, id uuid.UUID) *shared.DomainError
+	ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) *shared.DomainError
+	ConsumeTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, *shared.DomainError)
+	ConsumeRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, *shared.DomainError)
 }
 
 // UserService defines the methods that any
 type UserService interface {
-	CreateUser(req *dto.CreateUserRequest) (*dto.CreateUserResponse, *shared.DomainError)
-	GetUser() (*dto.GetUserResponse, *shared.DomainError)
+	CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.CreateUserResponse, *shared.DomainError)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*dto.UserDetail, *shared.DomainError)
+	UpdateProfile(ctx context.Context, id uuid.UUID, req *dto.UpdateProfileRequest) (*dto.UserDetail, *shared.DomainError)
+	ChangeEmail(ctx context.Context, id uuid.UUID, req *dto.ChangeEmailRequest) (*dto.UserDetail, *shared.DomainError)
+	DeleteUser(ctx context.Context, id uuid.UUID, client dto.ClientInfo) *shared.DomainError
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserDetail, *shared.DomainError)
+	ResendVerificationEmail(ctx context.Context, id uuid.UUID, client dto.ClientInfo) *shared.DomainError
+	ChangePassword(ctx context.Context, id uuid.UUID, req *dto.ChangePasswordRequest) *shared.DomainError
+	RequestPasswordReset(ctx context.Context, req *dto.ForgotPasswordRequest) *shared.DomainError
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) *shared.DomainError
+	Authenticate(ctx context.Context, email, password string) (*User, *shared.DomainError)
+	EnrollTOTP(ctx context.Context, id uuid.UUID) (*dto.TOTPEnrollmentResponse, *shared.DomainError)
+	ConfirmTOTP(ctx context.Context, id uuid.UUID, req *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, *shared.DomainError)
+	DisableTOTP(ctx context.Context, id uuid.UUID, req *dto.TOTPChangeRequest) *shared.DomainError
+	RegenerateRecoveryCodes(ctx context.Context, id uuid.UUID, req *dto.TOTPChangeRequest) (*dto.RecoveryCodesResponse, *shared.DomainError)
+	VerifySecondFactor(ctx context.Context, id uuid.UUID, code string) (*User, *shared.DomainError)
+}
+
+// PasswordHasher hashes and verifies user passwords. Implementations must
+// encode the algorithm and its parameters into the returned hash.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+// VerificationTokens issues and verifies signed email verification tokens.
+// A token is bound to the address it was issued for, so it stops working once
+// the email changes.
+type VerificationTokens interface {
+	IssueEmailVerificationToken(u *User) (string, error)
+	VerifyEmailVerificationToken(token string) (userID uuid.UUID, email string, err error)
+}
+
+// Notifier sends the transactional emails of the user domain.
+type Notifier interface {
+	SendEmailVerification(u *User, token string) error
+	SendPasswordReset(u *User, token string) error
+}
+
+// EmailVerified reports whether the user confirmed their current email.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled reports whether the user completed TOTP enrollment.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Disabled reports whether an admin disabled the account.
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
 }
 
 // BeforeAppendModel sets timestamps before insert/update.
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sortable user columns. Only these may be used to order listings.
+const (
+	SortByCreatedAt = "created_at"
+	SortByEmail     = "email"
+	SortByName      = "name"
+)
+
+// UserFilter selects a page of users using keyset pagination.
+type UserFilter struct {
+	EmailPrefix string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	Descending  bool
+	Limit       int
+	// After positions the page right after the given row. Nil returns the first page.
+	After *UserCursor
+}
+
+// UserCursor is the position of a row in a listing ordered by SortBy, with
+// the ID as a tie breaker.
+type UserCursor struct {
+	SortValue string
+	ID        uuid.UUID
+}
+
+// CursorFor returns the cursor pointing at u for the given sort column.
+func CursorFor(u *User, sortBy string) *UserCursor {
+	c := &UserCursor{ID: u.ID}
+	switch sortBy {
+	case SortByEmail:
+		c.SortValue = u.Email
+	case SortByName:
+		c.SortValue = u.Name
+	default:
+		c.SortValue = u.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
@@ -0,0 +1,20 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// PasswordResetToken is a single-use token mailed to a user who forgot their
+// password. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	bun.BaseModel `bun:"table:password_reset_tokens,alias:prt"`
+	ID            uuid.UUID  `bun:",pk,nullzero"`
+	UserID        uuid.UUID  `bun:",notnull"`
+	TokenHash     string     `bun:",notnull"`
+	ExpiresAt     time.Time  `bun:",notnull"`
+	UsedAt        *time.Time `bun:",nullzero"`
+	CreatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+}
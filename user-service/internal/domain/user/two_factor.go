@@ -0,0 +1,31 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// RecoveryCode is a single-use backup code for users who lost their
+// authenticator. Only the hash of the code is stored.
+type RecoveryCode struct {
+	bun.BaseModel `bun:"table:user_recovery_codes,alias:urc"`
+	ID            uuid.UUID  `bun:",pk,nullzero"`
+	UserID        uuid.UUID  `bun:",notnull"`
+	CodeHash      string     `bun:",notnull"`
+	UsedAt        *time.Time `bun:",nullzero"`
+	CreatedAt     time.Time  `bun:",nullzero,default:current_timestamp"`
+}
+
+// TOTPProvider generates and validates time-based one-time passwords and
+// encrypts TOTP secrets at rest.
+type TOTPProvider interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, account string) string
+	// Validate returns the time step the code matched so that callers can
+	// reject a code that was already used.
+	Validate(secret, code string) (int64, bool)
+	Seal(secret string) (string, error)
+	Open(sealed string) (string, error)
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Store keeps encoded values with an expiry.
+type Store interface {
+	// Get returns the value of key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// ProvideStore selects the store configured by cache.driver. It returns nil
+// when caching is turned off.
+func ProvideStore(cfg *config.Config, client *goredis.Client) (Store, error) {
+	if err := cfg.Cache.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Cache.Driver {
+	case "redis":
+		return NewRedisStore(client), nil
+	case "memory":
+		return NewMemoryStore(cfg.Cache.Size), nil
+	}
+	return nil, nil
+}
+
+// DecorateUserRepository puts the cache in front of the user repository
+// unless caching is turned off.
+func DecorateUserRepository(repo user.UserRepository, store Store, cfg *config.Config, log logger.Logger) user.UserRepository {
+	if store == nil {
+		return repo
+	}
+	return NewUserRepository(repo, store, cfg.Cache.TTL, log)
+}
@@ -0,0 +1,15 @@
+package cache
+
+import "go.uber.org/fx"
+
+// Module is not an fx.Module itself: a decoration only applies within the
+// module that declares it, and the user repository is used application-wide.
+var Module = fx.Options(
+	fx.Module(
+		"cache",
+		fx.Provide(
+			ProvideStore,
+		),
+	),
+	fx.Decorate(DecorateUserRepository),
+)
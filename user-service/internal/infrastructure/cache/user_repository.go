@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// cachedUser is the encoded form of user.User, which hides most of its
+// fields from JSON. The password hash and TOTP secret are left out so that
+// they never sit in Redis; the flows that check them read from the primary.
+type cachedUser struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at,omitempty"`
+	TOTPLastStep    int64      `json:"totp_last_step,omitempty"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UserRepository is a cache-aside decorator for user.UserRepository. Users
+// are cached by ID, and emails map to the ID so that one invalidation covers
+// both lookups. Concurrent misses for the same key share a single query.
+//
+// Contexts marked with shared.WithPrimaryReads bypass the cache. They must be
+// used by callers that need the password hash or TOTP secret, which cached
+// users do not carry. A lookup racing with a write may still cache the old
+// row, which the TTL bounds.
+type UserRepository struct {
+	user.UserRepository
+	store  Store
+	ttl    time.Duration
+	group  singleflight.Group
+	logger logger.Logger
+}
+
+// Compile-time interface check
+var _ user.UserRepository = (*UserRepository)(nil)
+
+func NewUserRepository(repo user.UserRepository, store Store, ttl time.Duration, log logger.Logger) *UserRepository {
+	return &UserRepository{
+		UserRepository: repo,
+		store:          store,
+		ttl:            ttl,
+		logger:         log.Named("cache"),
+	}
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*user.User, *shared.DomainError) {
+	if shared.PrimaryReads(ctx) {
+		return r.UserRepository.GetUserByID(ctx, id)
+	}
+	key := idKey(id)
+	if u := r.load(ctx, key); u != nil {
+		return u, nil
+	}
+	return r.fill(ctx, key, func(ctx context.Context) (*user.User, *shared.DomainError) {
+		return r.UserRepository.GetUserByID(ctx, id)
+	})
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*user.User, *shared.DomainError) {
+	if shared.PrimaryReads(ctx) {
+		return r.UserRepository.GetUserByEmail(ctx, email)
+	}
+	key := emailKey(email)
+	if raw, ok := r.get(ctx, key); ok {
+		// The mapping outlives email changes and deletions, so it only
+		// counts when the user still has the address.
+		if id, err := uuid.ParseBytes(raw); err == nil {
+			u, err := r.GetUserByID(ctx, id)
+			if err == nil && u.Email == email {
+				return u, nil
+			}
+			if err != nil && err.StatusCode != 404 {
+				return nil, err
+			}
+		}
+	}
+	return r.fill(ctx, key, func(ctx context.Context) (*user.User, *shared.DomainError) {
+		return r.UserRepository.GetUserByEmail(ctx, email)
+	})
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, u *user.User) *shared.DomainError {
+	defer r.invalidate(ctx, u.ID)
+	return r.UserRepository.UpdateUser(ctx, u)
+}
+
+func (r *UserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string, actor audit.Actor) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.UpdateEmail(ctx, id, email, actor)
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.UpdatePassword(ctx, id, passwordHash)
+}
+
+func (r *UserRepository) ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, actor audit.Actor) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.ChangePassword(ctx, id, passwordHash, actor)
+}
+
+func (r *UserRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string, actor audit.Actor) (uuid.UUID, *shared.DomainError) {
+	id, err := r.UserRepository.ResetPassword(ctx, tokenHash, passwordHash, actor)
+	if id != uuid.Nil {
+		r.invalidate(ctx, id)
+	}
+	return id, err
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.MarkEmailVerified(ctx, id, email)
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID, actor audit.Actor) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.DeleteUser(ctx, id, actor)
+}
+
+func (r *UserRepository) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool, actor audit.Actor) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.SetDisabled(ctx, id, disabled, actor)
+}
+
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id uuid.UUID, sealedSecret string) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.SetTOTPSecret(ctx, id, sealedSecret)
+}
+
+func (r *UserRepository) EnableTOTP(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.EnableTOTP(ctx, id, recoveryCodeHashes)
+}
+
+func (r *UserRepository) DisableTOTP(ctx context.Context, id uuid.UUID) *shared.DomainError {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.DisableTOTP(ctx, id)
+}
+
+func (r *UserRepository) ConsumeTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, *shared.DomainError) {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.ConsumeTOTPStep(ctx, id, step)
+}
+
+// fill loads a missed key once for all concurrent callers and caches the
+// result. The query is detached from the caller's cancellation, since other
+// callers may be waiting for it; the repository's query timeout still applies.
+func (r *UserRepository) fill(ctx context.Context, key string, query func(context.Context) (*user.User, *shared.DomainError)) (*user.User, *shared.DomainError) {
+	v, err, _ := r.group.Do(key, func() (any, error) {
+		u, err := query(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		r.save(context.WithoutCancel(ctx), u)
+		return u, nil
+	})
+	if err != nil {
+		return nil, err.(*shared.DomainError)
+	}
+	// Every caller gets its own copy to modify, without the fields that are
+	// not cached, so that it sees the same user as on a cache hit
+	u := *v.(*user.User)
+	u.Password, u.TOTPSecret = "", ""
+	return &u, nil
+}
+
+// load returns the cached user of key, or nil on a miss.
+func (r *UserRepository) load(ctx context.Context, key string) *user.User {
+	raw, ok := r.get(ctx, key)
+	if !ok {
+		return nil
+	}
+	var c cachedUser
+	if err := json.Unmarshal(raw, &c); err != nil {
+		r.logger.Warn("discarding undecodable cache entry", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return &user.User{
+		ID:              c.ID,
+		Name:            c.Name,
+		Email:           c.Email,
+		EmailVerifiedAt: c.EmailVerifiedAt,
+		TOTPEnabledAt:   c.TOTPEnabledAt,
+		TOTPLastStep:    c.TOTPLastStep,
+		DisabledAt:      c.DisabledAt,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}
+}
+
+// get reads key from the store. A failing store is logged and treated as a
+// miss, so an unavailable Redis only costs the extra queries.
+func (r *UserRepository) get(ctx context.Context, key string) ([]byte, bool) {
+	raw, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.logger.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	return raw, ok
+}
+
+func (r *UserRepository) save(ctx context.Context, u *user.User) {
+	raw, err := json.Marshal(cachedUser{
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		TOTPEnabledAt:   u.TOTPEnabledAt,
+		TOTPLastStep:    u.TOTPLastStep,
+		DisabledAt:      u.DisabledAt,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	})
+	if err != nil {
+		r.logger.Warn("cache encode failed", zap.String("user_id", u.ID.String()), zap.Error(err))
+		return
+	}
+
+	ttl := r.jitteredTTL()
+	if err := r.store.Set(ctx, idKey(u.ID), raw, ttl); err != nil {
+		r.logger.Warn("cache write failed", zap.String("user_id", u.ID.String()), zap.Error(err))
+		return
+	}
+	if err := r.store.Set(ctx, emailKey(u.Email), []byte(u.ID.String()), ttl); err != nil {
+		r.logger.Warn("cache write failed", zap.String("user_id", u.ID.String()), zap.Error(err))
+	}
+}
+
+// invalidate drops the cached user of id and keeps waiting lookups from
+// joining a query that started before the write.
+func (r *UserRepository) invalidate(ctx context.Context, id uuid.UUID) {
+	key := idKey(id)
+	r.group.Forget(key)
+	if err := r.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		r.logger.Error("cache invalidation failed", zap.String("user_id", id.String()), zap.Error(err))
+	}
+}
+
+// jitteredTTL spreads expiries by up to a tenth of the TTL, so that entries
+// cached together do not all expire at once.
+func (r *UserRepository) jitteredTTL() time.Duration {
+	return r.ttl + rand.N(r.ttl/10+1)
+}
+
+func idKey(id uuid.UUID) string {
+	return "user:id:" + id.String()
+}
+
+func emailKey(email string) string {
+	return "user:email:" + email
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type nopLogger struct{ logger.Logger }
+
+func (l nopLogger) Named(string) logger.Logger { return l }
+func (nopLogger) Warn(string, ...zap.Field)    {}
+func (nopLogger) Error(string, ...zap.Field)   {}
+
+// fakeUserRepo keeps users in memory and counts the lookups that reach it.
+type fakeUserRepo struct {
+	user.UserRepository
+	users   map[uuid.UUID]user.User
+	queries int
+}
+
+func (f *fakeUserRepo) GetUserByID(_ context.Context, id uuid.UUID) (*user.User, *shared.DomainError) {
+	f.queries++
+	if u, ok := f.users[id]; ok {
+		return &u, nil
+	}
+	return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+}
+
+func (f *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (*user.User, *shared.DomainError) {
+	f.queries++
+	for _, u := range f.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+}
+
+func (f *fakeUserRepo) UpdateUser(_ context.Context, u *user.User) *shared.DomainError {
+	stored := f.users[u.ID]
+	stored.Name = u.Name
+	f.users[u.ID] = stored
+	return nil
+}
+
+func (f *fakeUserRepo) UpdateEmail(_ context.Context, id uuid.UUID, email string, _ audit.Actor) *shared.DomainError {
+	stored := f.users[id]
+	stored.Email = email
+	f.users[id] = stored
+	return nil
+}
+
+func (f *fakeUserRepo) SetDisabled(_ context.Context, id uuid.UUID, disabled bool, _ audit.Actor) *shared.DomainError {
+	stored := f.users[id]
+	stored.DisabledAt = nil
+	if disabled {
+		now := time.Now()
+		stored.DisabledAt = &now
+	}
+	f.users[id] = stored
+	return nil
+}
+
+func newTestRepo() (*UserRepository, *fakeUserRepo, *MemoryStore, *user.User) {
+	u := user.User{
+		ID:         uuid.New(),
+		Name:       "Jane",
+		Email:      "jane@example.com",
+		Password:   "$argon2id$secret-hash",
+		TOTPSecret: "sealed-totp-secret",
+	}
+	db := &fakeUserRepo{users: map[uuid.UUID]user.User{u.ID: u}}
+	store := NewMemoryStore(100)
+	return NewUserRepository(db, store, time.Minute, nopLogger{}), db, store, &u
+}
+
+func TestUserRepositoryCachesByID(t *testing.T) {
+	repo, db, _, u := newTestRepo()
+	ctx := context.Background()
+
+	for range 3 {
+		got, err := repo.GetUserByID(ctx, u.ID)
+		if err != nil || got.Email != u.Email {
+			t.Fatalf("GetUserByID = %+v, %v", got, err)
+		}
+	}
+	if db.queries != 1 {
+		t.Errorf("database queried %d times, want 1", db.queries)
+	}
+
+	if _, err := repo.GetUserByID(shared.WithPrimaryReads(ctx), u.ID); err != nil {
+		t.Fatalf("GetUserByID from the primary: %v", err)
+	}
+	if db.queries != 2 {
+		t.Errorf("primary read served from the cache")
+	}
+}
+
+func TestUserRepositoryDoesNotCacheSecrets(t *testing.T) {
+	repo, _, store, u := newTestRepo()
+	ctx := context.Background()
+
+	miss, _ := repo.GetUserByID(ctx, u.ID)
+	hit, _ := repo.GetUserByID(ctx, u.ID)
+	for name, got := range map[string]*user.User{"miss": miss, "hit": hit} {
+		if got.Password != "" || got.TOTPSecret != "" {
+			t.Errorf("%s returned password %q and totp secret %q, want neither", name, got.Password, got.TOTPSecret)
+		}
+	}
+
+	raw, _, _ := store.Get(ctx, idKey(u.ID))
+	if strings.Contains(string(raw), u.Password) || strings.Contains(string(raw), u.TOTPSecret) {
+		t.Errorf("cache entry %s holds a secret", raw)
+	}
+
+	primary, _ := repo.GetUserByID(shared.WithPrimaryReads(ctx), u.ID)
+	if primary.Password != u.Password || primary.TOTPSecret != u.TOTPSecret {
+		t.Error("primary read is missing the secrets")
+	}
+}
+
+func TestUserRepositoryInvalidatesOnWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(r *UserRepository, id uuid.UUID) *shared.DomainError
+		check func(u *user.User) bool
+	}{
+		{
+			"UpdateUser",
+			func(r *UserRepository, id uuid.UUID) *shared.DomainError {
+				return r.UpdateUser(context.Background(), &user.User{ID: id, Name: "Janet"})
+			},
+			func(u *user.User) bool { return u.Name == "Janet" },
+		},
+		{
+			"SetDisabled",
+			func(r *UserRepository, id uuid.UUID) *shared.DomainError {
+				return r.SetDisabled(context.Background(), id, true, audit.Actor{})
+			},
+			func(u *user.User) bool { return u.Disabled() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _, _, u := newTestRepo()
+			ctx := context.Background()
+
+			if _, err := repo.GetUserByID(ctx, u.ID); err != nil {
+				t.Fatalf("GetUserByID: %v", err)
+			}
+			if err := tt.write(repo, u.ID); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			got, err := repo.GetUserByID(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("GetUserByID after the write: %v", err)
+			}
+			if !tt.check(got) {
+				t.Errorf("GetUserByID after the write = %+v, want the new state", got)
+			}
+		})
+	}
+}
+
+// The email to ID mapping outlives an email change, so a lookup of the old
+// address must not return the user.
+func TestUserRepositoryEmailMappingAfterUpdateEmail(t *testing.T) {
+	repo, _, _, u := newTestRepo()
+	ctx := context.Background()
+
+	if got, err := repo.GetUserByEmail(ctx, "jane@example.com"); err != nil || got.ID != u.ID {
+		t.Fatalf("GetUserByEmail = %+v, %v", got, err)
+	}
+	if err := repo.UpdateEmail(ctx, u.ID, "jane@example.org", audit.Actor{}); err != nil {
+		t.Fatalf("UpdateEmail: %v", err)
+	}
+
+	if got, err := repo.GetUserByEmail(ctx, "jane@example.com"); err == nil || err.StatusCode != 404 {
+		t.Errorf("GetUserByEmail of the old address = %+v, %v; want 404", got, err)
+	}
+	got, err := repo.GetUserByEmail(ctx, "jane@example.org")
+	if err != nil || got.ID != u.ID || got.Email != "jane@example.org" {
+		t.Errorf("GetUserByEmail of the new address = %+v, %v; want the user", got, err)
+	}
+}
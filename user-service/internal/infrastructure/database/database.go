@@ -1,11 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"runtime"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Nezent/microservice-template/user-service/config"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -13,52 +19,89 @@ import (
 )
 
 // Database wraps the Bun DB connection and provides database access methods.
+// DB is the primary; reads that tolerate replication lag can be served by a
+// replica through Reader.
 type Database struct {
-	DB *bun.DB
+	DB           *bun.DB
+	replicas     []*replica
+	next         atomic.Uint64
+	queryTimeout time.Duration
+	stop         chan struct{}
+	stopOnce     sync.Once
 }
 
-// NewDatabase initializes and returns a new Database instance.
-func NewDatabase() (*Database, error) {
-	// Get database configuration from environment variables with defaults
-	host := getEnv("POSTGRES_HOST", "db")
-	user := getEnv("POSTGRES_USER", "nezent")
-	password := getEnv("POSTGRES_PASSWORD", "123456")
-	dbname := getEnv("POSTGRES_DB", "nezent_db")
-	sslmode := getEnv("POSTGRES_SSL_MODE", "disable")
-
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s", user, password, host, dbname, sslmode)
+// NewDatabase initializes the connection selected by the database config.
+// Replicas start out unhealthy and serve no reads until MonitorReplicas has
+// reached them.
+func NewDatabase(cfg *config.Config) (*Database, error) {
+	if err := cfg.Database.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
 
-	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn)))
+	primary, err := open(cfg.Database.Driver())
+	if err != nil {
+		return nil, err
+	}
+	db := &Database{
+		DB:           primary,
+		queryTimeout: cfg.Database.QueryTimeout,
+		stop:         make(chan struct{}),
+	}
+	for _, conn := range cfg.Database.Replicas() {
+		handle, err := open(conn)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		db.replicas = append(db.replicas, &replica{
+			db:   handle,
+			addr: net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port)),
+		})
+	}
+	return db, nil
+}
 
-	maxOpenConns := 4 * runtime.GOMAXPROCS(0)
-	sqldb.SetMaxOpenConns(maxOpenConns)
-	sqldb.SetMaxIdleConns(maxOpenConns)
+// open creates the bun DB for conn with the dialect of its driver. Only
+// PostgreSQL is supported; the config rejects other databases.
+func open(conn config.DBInstanceConfig) (*bun.DB, error) {
+	var db *bun.DB
+	switch conn.Driver {
+	case "pgsql", "postgres", "postgresql":
+		sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(conn.BuildDsn())))
+		db = bun.NewDB(sqldb, pgdialect.New())
+	default:
+		return nil, fmt.Errorf("unknown database driver: %q", conn.Driver)
+	}
 
-	db := bun.NewDB(sqldb, pgdialect.New())
+	pool := conn.Pool
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
 	db.AddQueryHook(bundebug.NewQueryHook(
 		bundebug.WithVerbose(true),
 		bundebug.FromEnv("BUNDEBUG"),
 	))
-	return &Database{
-		DB: db,
-	}, nil
+	return db, nil
 }
 
-// Close closes the database connection.
+// WithTimeout derives a context for a single query that is cancelled after
+// the configured query timeout or when ctx is done, whichever comes first.
+func (db *Database) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// Close stops the replica health checks and closes every connection.
 func (db *Database) Close() error {
-	return db.DB.DB.Close()
+	db.stopOnce.Do(func() { close(db.stop) })
+	errs := []error{db.DB.Close()}
+	for _, r := range db.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
 }
 
 // RawSQLDB returns the underlying *sql.DB instance.
 func (db *Database) RawSQLDB() *sql.DB {
 	return db.DB.DB
 }
-
-// getEnv gets an environment variable with a fallback value
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// migrationLockTimeout is how long a replica waits for another one to finish
+// migrating.
+const migrationLockTimeout = 5 * time.Minute
+
+// StartTimeout bounds the application's start. With
+// database.migrate_on_startup the start waits for the migration lock and
+// applies the migrations, which fx's default of 15 seconds does not cover.
+const StartTimeout = migrationLockTimeout + 2*time.Minute
+
+// Migrator applies the migrations embedded in the binary. Runs that change
+// the schema hold a Postgres advisory lock, so replicas starting at the same
+// time apply each migration only once.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// NewMigrator creates a Migrator for db.
+func NewMigrator(db *Database) (*Migrator, error) {
+	// Poll every second while another replica migrates
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockTimeout(1, uint64(migrationLockTimeout/time.Second)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+	return newMigrator(db.RawSQLDB(), goose.WithSessionLocker(locker))
+}
+
+func newMigrator(db *sql.DB, opts ...goose.ProviderOption) (*Migrator, error) {
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down rolls back the most recent migration.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// Redo rolls back the most recent migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+	up, err := m.provider.UpByOne(ctx)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// Status reports which migrations have been applied.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// CheckMigrations applies every migration, rolls all of them back and applies
+// them again in a scratch database created next to the configured one, so a
+// broken Down section fails here instead of during a rollback. The scratch
+// database is dropped afterwards and the configured one is never touched.
+func CheckMigrations(ctx context.Context, cfg *config.Config) error {
+	db, err := NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Only PostgreSQL is supported by NewDatabase, so the scratch database is
+	// configured through the postgres section.
+	scratchCfg := *cfg
+	scratchCfg.Database.Postgres.Name = cfg.Database.Driver().Name + "_migrate_check"
+	name := bun.Ident(scratchCfg.Database.Postgres.Name)
+
+	if _, err := db.DB.ExecContext(ctx, "DROP DATABASE IF EXISTS ?", name); err != nil {
+		return fmt.Errorf("failed to drop scratch database: %w", err)
+	}
+	if _, err := db.DB.ExecContext(ctx, "CREATE DATABASE ?", name); err != nil {
+		return fmt.Errorf("failed to create scratch database: %w", err)
+	}
+
+	checkErr := roundTrip(ctx, &scratchCfg)
+	_, dropErr := db.DB.ExecContext(ctx, "DROP DATABASE IF EXISTS ?", name)
+	if dropErr != nil {
+		dropErr = fmt.Errorf("failed to drop scratch database: %w", dropErr)
+	}
+	return errors.Join(checkErr, dropErr)
+}
+
+func roundTrip(ctx context.Context, cfg *config.Config) error {
+	db, err := NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := newMigrator(db.RawSQLDB())
+	if err != nil {
+		return err
+	}
+	if _, err := m.provider.Up(ctx); err != nil {
+		return fmt.Errorf("up: %w", err)
+	}
+	if _, err := m.provider.DownTo(ctx, 0); err != nil {
+		return fmt.Errorf("down: %w", err)
+	}
+	if _, err := m.provider.Up(ctx); err != nil {
+		return fmt.Errorf("up after down: %w", err)
+	}
+	return nil
+}
+
+// migrateOnStart applies pending migrations before the server starts when
+// database.migrate_on_startup is enabled.
+func migrateOnStart(lc fx.Lifecycle, cfg *config.Config, db *Database, log logger.Logger) {
+	if !cfg.Database.MigrateOnStartup {
+		return
+	}
+	log = log.Named("migrate")
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			m, err := NewMigrator(db)
+			if err != nil {
+				return err
+			}
+			results, err := m.Up(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to apply migrations: %w", err)
+			}
+			for _, r := range results {
+				log.Info("migration applied",
+					zap.String("migration", r.Source.Path),
+					zap.Duration("duration", r.Duration),
+				)
+			}
+			return nil
+		},
+	})
+}
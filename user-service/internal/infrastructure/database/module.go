@@ -5,6 +5,7 @@ import "go.uber.org/fx"
 var Module = fx.Module(
 	"database",
 	fx.Provide(
-		NewDatabase,
+		ProvideDatabase,
 	),
+	fx.Invoke(migrateOnStart),
 )
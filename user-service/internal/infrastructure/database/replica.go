@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// replica is a read-only connection and the result of its last health check.
+type replica struct {
+	db      *bun.DB
+	addr    string
+	healthy atomic.Bool
+}
+
+// Reader returns the connection a read should use: a healthy replica, picked
+// round robin, or the primary when ctx requires primary reads or no replica
+// is healthy. Writes and transactions always use DB.
+func (db *Database) Reader(ctx context.Context) bun.IDB {
+	if len(db.replicas) == 0 || shared.PrimaryReads(ctx) {
+		return db.DB
+	}
+	start := db.next.Add(1)
+	for i := range db.replicas {
+		r := db.replicas[(start+uint64(i))%uint64(len(db.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return db.DB
+}
+
+// MonitorReplicas pings every replica right away and then once per interval
+// until Close, so that Reader only hands out replicas that answer.
+func (db *Database) MonitorReplicas(interval time.Duration, log logger.Logger) {
+	if len(db.replicas) == 0 {
+		return
+	}
+	db.checkReplicas(log)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-db.stop:
+				return
+			case <-ticker.C:
+				db.checkReplicas(log)
+			}
+		}
+	}()
+}
+
+func (db *Database) checkReplicas(log logger.Logger) {
+	for _, r := range db.replicas {
+		ctx, cancel := db.WithTimeout(context.Background())
+		err := r.db.PingContext(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			log.Info("replica is healthy", zap.String("replica", r.addr))
+		} else {
+			log.Warn("replica is unhealthy, reading from the primary",
+				zap.String("replica", r.addr),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// ProvideDatabase provides the database for dependency injection and ties
+// replica health checks and connections to the application lifecycle.
+func ProvideDatabase(lc fx.Lifecycle, cfg *config.Config, log logger.Logger) (*Database, error) {
+	db, err := NewDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			db.MonitorReplicas(cfg.Database.ReplicaCheckInterval, log.Named("database"))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			return db.Close()
+		},
+	})
+	return db, nil
+}
@@ -0,0 +1,123 @@
+package hashing
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Time    = 3
+	defaultArgon2Threads = 2
+)
+
+// argon2Params are the tunable argon2id parameters stored alongside each hash.
+type argon2Params struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// Argon2idHasher hashes passwords with argon2id using the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	params argon2Params
+}
+
+func NewArgon2idHasher(cfg config.ArgonHashConfig) *Argon2idHasher {
+	p := argon2Params{
+		Memory:  cfg.Memory,
+		Time:    cfg.Time,
+		Threads: cfg.Threads,
+	}
+	if p.Memory == 0 {
+		p.Memory = defaultArgon2Memory
+	}
+	if p.Time == 0 {
+		p.Time = defaultArgon2Time
+	}
+	if p.Threads == 0 {
+		p.Threads = defaultArgon2Threads
+	}
+	return &Argon2idHasher{params: p}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Time, h.params.Memory, h.params.Threads, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory, h.params.Time, h.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return params != h.params || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+}
+
+func (h *Argon2idHasher) Matches(encoded string) bool {
+	return hasPrefix(encoded, "$argon2id$")
+}
+
+func decodeArgon2(encoded string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	return params, salt, key, nil
+}
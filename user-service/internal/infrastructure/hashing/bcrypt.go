@@ -0,0 +1,85 @@
+package hashing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultBcryptRounds = 12
+
+	// bcryptSHA256Prefix marks hashes whose password was pre-hashed with
+	// SHA-256 before bcrypt. bcrypt only reads the first 72 bytes of its
+	// input and x/crypto rejects anything longer, so pre-hashing lets every
+	// password the API accepts be hashed in full.
+	bcryptSHA256Prefix = "$bcrypt-sha256$"
+)
+
+// BcryptHasher hashes passwords with bcrypt over a base64 SHA-256 digest of
+// the password. The result is the standard modular crypt format behind a
+// marker ($bcrypt-sha256$2a$12$...). Plain bcrypt hashes ($2a$12$...) are
+// still verified and reported as needing a rehash.
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cfg config.BcryptHashConfig) *BcryptHasher {
+	cost := cfg.Rounds
+	if cost == 0 {
+		cost = defaultBcryptRounds
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(bcryptPrehash(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return bcryptSHA256Prefix + strings.TrimPrefix(string(hash), "$"), nil
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	input := []byte(password)
+	if inner, ok := strings.CutPrefix(encoded, bcryptSHA256Prefix); ok {
+		encoded, input = "$"+inner, bcryptPrehash(password)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), input)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	inner, ok := strings.CutPrefix(encoded, bcryptSHA256Prefix)
+	if !ok {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte("$" + inner))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
+
+func (h *BcryptHasher) Matches(encoded string) bool {
+	return hasPrefix(encoded, bcryptSHA256Prefix, "$2a$", "$2b$", "$2y$")
+}
+
+// bcryptPrehash reduces a password of any length to 44 bytes of base64, which
+// also keeps NUL bytes out of bcrypt's input.
+func bcryptPrehash(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(sum[:]))
+}
@@ -0,0 +1,102 @@
+package hashing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+)
+
+const (
+	DriverArgon2id = "argon2id"
+	DriverBcrypt   = "bcrypt"
+)
+
+// ErrUnknownHashFormat is returned when an encoded hash does not match any supported algorithm.
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// hasher is implemented by every supported algorithm.
+type hasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+	Matches(encoded string) bool
+}
+
+// Manager hashes new passwords with the configured driver and verifies
+// hashes produced by any supported driver, so the default algorithm can be
+// changed without invalidating existing credentials.
+type Manager struct {
+	driver  hasher
+	hashers []hasher
+}
+
+// Compile-time interface check
+var _ user.PasswordHasher = (*Manager)(nil)
+
+// NewManager creates a Manager from the hashing configuration.
+func NewManager(cfg config.HashingConfig) (*Manager, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hashing config: %w", err)
+	}
+
+	argon := NewArgon2idHasher(cfg.Argon)
+	bcrypt := NewBcryptHasher(cfg.Bcrypt)
+
+	m := &Manager{hashers: []hasher{argon, bcrypt}}
+	switch cfg.Driver {
+	case DriverArgon2id:
+		m.driver = argon
+	case DriverBcrypt:
+		m.driver = bcrypt
+	}
+	return m, nil
+}
+
+// Hash hashes the password with the configured driver.
+func (m *Manager) Hash(password string) (string, error) {
+	return m.driver.Hash(password)
+}
+
+// Verify reports whether the password matches the encoded hash.
+func (m *Manager) Verify(password, encoded string) (bool, error) {
+	h, err := m.lookup(encoded)
+	if err != nil {
+		return false, err
+	}
+	return h.Verify(password, encoded)
+}
+
+// NeedsRehash reports whether the encoded hash was produced by a different
+// driver or with different parameters than the current configuration.
+func (m *Manager) NeedsRehash(encoded string) bool {
+	if !m.driver.Matches(encoded) {
+		return true
+	}
+	return m.driver.NeedsRehash(encoded)
+}
+
+func (m *Manager) lookup(encoded string) (hasher, error) {
+	for _, h := range m.hashers {
+		if h.Matches(encoded) {
+			return h, nil
+		}
+	}
+	return nil, ErrUnknownHashFormat
+}
+
+// ProvideManager provides a hashing manager for dependency injection.
+func ProvideManager(cfg *config.Config) (*Manager, error) {
+	return NewManager(cfg.Hashing)
+}
+
+func hasPrefix(encoded string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(encoded, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package hashing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; the algorithms are the same.
+var (
+	testArgon  = config.ArgonHashConfig{Memory: 64, Time: 1, Threads: 1}
+	testBcrypt = config.BcryptHashConfig{Rounds: 10}
+)
+
+func newTestManager(t *testing.T, driver string, argon config.ArgonHashConfig, bcrypt config.BcryptHashConfig) *Manager {
+	t.Helper()
+	m, err := NewManager(config.HashingConfig{Driver: driver, Argon: argon, Bcrypt: bcrypt})
+	if err != nil {
+		t.Fatalf("NewManager(%s): %v", driver, err)
+	}
+	return m
+}
+
+func TestManagerHashAndVerify(t *testing.T) {
+	for _, driver := range []string{DriverArgon2id, DriverBcrypt} {
+		t.Run(driver, func(t *testing.T) {
+			m := newTestManager(t, driver, testArgon, testBcrypt)
+			hash, err := m.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+
+			tests := []struct {
+				name     string
+				password string
+				want     bool
+			}{
+				{"matching password", "correct horse battery staple", true},
+				{"wrong password", "correct horse battery stapler", false},
+				{"empty password", "", false},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ok, err := m.Verify(tt.password, hash)
+					if err != nil {
+						t.Fatalf("Verify: %v", err)
+					}
+					if ok != tt.want {
+						t.Errorf("Verify = %v, want %v", ok, tt.want)
+					}
+				})
+			}
+			if m.NeedsRehash(hash) {
+				t.Error("NeedsRehash of a fresh hash = true, want false")
+			}
+		})
+	}
+}
+
+func TestManagerHashesLongPasswords(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	for _, driver := range []string{DriverArgon2id, DriverBcrypt} {
+		t.Run(driver, func(t *testing.T) {
+			m := newTestManager(t, driver, testArgon, testBcrypt)
+			hash, err := m.Hash(long)
+			if err != nil {
+				t.Fatalf("Hash of a 100-byte password: %v", err)
+			}
+			if ok, err := m.Verify(long, hash); err != nil || !ok {
+				t.Errorf("Verify = %v, %v; want true, nil", ok, err)
+			}
+			// Only the 100th byte differs, past bcrypt's 72-byte limit.
+			if ok, _ := m.Verify(long[:99]+"b", hash); ok {
+				t.Error("Verify accepted a password differing after byte 72")
+			}
+		})
+	}
+}
+
+func TestBcryptVerifiesLegacyHashes(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), testBcrypt.Rounds)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	m := newTestManager(t, DriverBcrypt, testArgon, testBcrypt)
+	if ok, err := m.Verify("password", string(legacy)); err != nil || !ok {
+		t.Errorf("Verify of a plain bcrypt hash = %v, %v; want true, nil", ok, err)
+	}
+	if !m.NeedsRehash(string(legacy)) {
+		t.Error("NeedsRehash of a plain bcrypt hash = false, want true")
+	}
+}
+
+func TestManagerHashIsSalted(t *testing.T) {
+	m := newTestManager(t, DriverArgon2id, testArgon, testBcrypt)
+	a, _ := m.Hash("password")
+	b, _ := m.Hash("password")
+	if a == b {
+		t.Error("two hashes of the same password are equal")
+	}
+}
+
+func TestManagerNeedsRehash(t *testing.T) {
+	argonHash, _ := newTestManager(t, DriverArgon2id, testArgon, testBcrypt).Hash("password")
+	bcryptHash, _ := newTestManager(t, DriverBcrypt, testArgon, testBcrypt).Hash("password")
+
+	stronger := config.ArgonHashConfig{Memory: 128, Time: 2, Threads: 1}
+	tests := []struct {
+		name    string
+		manager *Manager
+		encoded string
+		want    bool
+	}{
+		{"same argon2id parameters", newTestManager(t, DriverArgon2id, testArgon, testBcrypt), argonHash, false},
+		{"changed argon2id parameters", newTestManager(t, DriverArgon2id, stronger, testBcrypt), argonHash, true},
+		{"same bcrypt cost", newTestManager(t, DriverBcrypt, testArgon, testBcrypt), bcryptHash, false},
+		{"changed bcrypt cost", newTestManager(t, DriverBcrypt, testArgon, config.BcryptHashConfig{Rounds: 11}), bcryptHash, true},
+		{"bcrypt hash under argon2id driver", newTestManager(t, DriverArgon2id, testArgon, testBcrypt), bcryptHash, true},
+		{"argon2id hash under bcrypt driver", newTestManager(t, DriverBcrypt, testArgon, testBcrypt), argonHash, true},
+		{"malformed argon2id hash", newTestManager(t, DriverArgon2id, testArgon, testBcrypt), "$argon2id$v=19$broken", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.manager.NeedsRehash(tt.encoded); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerVerifiesOtherDriversHashes(t *testing.T) {
+	bcryptHash, _ := newTestManager(t, DriverBcrypt, testArgon, testBcrypt).Hash("password")
+	m := newTestManager(t, DriverArgon2id, testArgon, testBcrypt)
+
+	ok, err := m.Verify("password", bcryptHash)
+	if err != nil || !ok {
+		t.Errorf("Verify of a bcrypt hash under argon2id = %v, %v; want true, nil", ok, err)
+	}
+}
+
+func TestManagerVerifyRejectsUnknownFormats(t *testing.T) {
+	m := newTestManager(t, DriverArgon2id, testArgon, testBcrypt)
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"plain text", "password"},
+		{"empty", ""},
+		{"md5 crypt", "$1$salt$hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := m.Verify("password", tt.encoded)
+			if ok || !errors.Is(err, ErrUnknownHashFormat) {
+				t.Errorf("Verify = %v, %v; want false, ErrUnknownHashFormat", ok, err)
+			}
+		})
+	}
+}
+
+func TestArgon2idVerifyRejectsTamperedHash(t *testing.T) {
+	h := NewArgon2idHasher(testArgon)
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"wrong version", "$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA"},
+		{"bad parameters", "$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA"},
+		{"bad salt encoding", "$argon2id$v=19$m=64,t=1,p=1$!!!$aGFzaA"},
+		{"missing hash", "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, err := h.Verify("password", tt.encoded); ok || err == nil {
+				t.Errorf("Verify = %v, %v; want false and an error", ok, err)
+			}
+		})
+	}
+}
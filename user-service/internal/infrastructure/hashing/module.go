@@ -0,0 +1,16 @@
+package hashing
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"hashing",
+	fx.Provide(
+		fx.Annotate(
+			ProvideManager,
+			fx.As(new(user.PasswordHasher)),
+		),
+	),
+)
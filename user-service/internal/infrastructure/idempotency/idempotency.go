@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// ProvideStore selects the store configured by idempotency.driver. The
+// postgres store sweeps expired keys while the application runs.
+func ProvideStore(lc fx.Lifecycle, cfg *config.Config, db *database.Database, client *goredis.Client, log logger.Logger) (router.IdempotencyStore, error) {
+	if err := cfg.Idempotency.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Idempotency.Driver == "redis" {
+		return NewRedisStore(client), nil
+	}
+
+	store := NewPostgresStore(db)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			store.Sweep(cfg.Idempotency.SweepInterval, log.Named("idempotency"))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			store.Close()
+			return nil
+		},
+	})
+	return store, nil
+}
+
+func ProvideMiddleware(cfg *config.Config, store router.IdempotencyStore) *router.IdempotencyMiddleware {
+	return router.NewIdempotencyMiddleware(store, []byte(cfg.Idempotency.Secret), cfg.Idempotency.TTL, cfg.Idempotency.LockTTL)
+}
@@ -0,0 +1,11 @@
+package idempotency
+
+import "go.uber.org/fx"
+
+var Module = fx.Module(
+	"idempotency",
+	fx.Provide(
+		ProvideStore,
+		ProvideMiddleware,
+	),
+)
@@ -0,0 +1,186 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"github.com/uptrace/bun"
+	"go.uber.org/zap"
+)
+
+// sweepBatchSize bounds how many expired keys one DELETE removes, so the
+// sweep never holds many row locks or runs into the statement timeout.
+const sweepBatchSize = 1000
+
+// idempotencyKey is the Postgres row behind a router.IdempotencyRecord.
+type idempotencyKey struct {
+	bun.BaseModel   `bun:"table:idempotency_keys,alias:ik"`
+	Key             string      `bun:",pk"`
+	Fingerprint     string      `bun:",notnull"`
+	StatusCode      int         `bun:",nullzero"`
+	ResponseHeaders http.Header `bun:"type:jsonb,nullzero"`
+	ResponseBody    []byte      `bun:",nullzero"`
+	ExpiresAt       time.Time   `bun:",notnull"`
+	CreatedAt       time.Time   `bun:",nullzero,default:current_timestamp"`
+}
+
+// PostgresStore keeps idempotency keys in the idempotency_keys table.
+// Expired keys are deleted by Sweep.
+type PostgresStore struct {
+	db       *database.Database
+	stop     chan struct{}
+	stopOnce sync.Once
+	swept    sync.WaitGroup
+}
+
+// Compile-time interface check
+var _ router.IdempotencyStore = (*PostgresStore)(nil)
+
+func NewPostgresStore(db *database.Database) *PostgresStore {
+	return &PostgresStore{
+		db:   db,
+		stop: make(chan struct{}),
+	}
+}
+
+func (s *PostgresStore) Reserve(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*router.IdempotencyRecord, bool, error) {
+	ctx, cancel := s.db.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	// Expired keys are taken over in place so they do not block reuse.
+	res, err := s.db.DB.NewInsert().
+		Model(&idempotencyKey{Key: key, Fingerprint: fingerprint, ExpiresAt: now.Add(lockTTL), CreatedAt: now}).
+		On("CONFLICT (key) DO UPDATE").
+		Set("fingerprint = EXCLUDED.fingerprint").
+		Set("status_code = NULL").
+		Set("response_headers = NULL").
+		Set("response_body = NULL").
+		Set("expires_at = EXCLUDED.expires_at").
+		Set("created_at = EXCLUDED.created_at").
+		Where("ik.expires_at < ?", now).
+		Exec(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, true, nil
+	}
+
+	row := new(idempotencyKey)
+	err = s.db.DB.NewSelect().Model(row).Where("ik.key = ?", key).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The key expired and was removed in between; report it as busy
+			// and let the client retry.
+			return &router.IdempotencyRecord{Fingerprint: fingerprint}, false, nil
+		}
+		return nil, false, err
+	}
+	return &router.IdempotencyRecord{
+		Fingerprint: row.Fingerprint,
+		StatusCode:  row.StatusCode,
+		Header:      row.ResponseHeaders,
+		Body:        row.ResponseBody,
+	}, false, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key string, record *router.IdempotencyRecord, ttl time.Duration) error {
+	ctx, cancel := s.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.DB.NewUpdate().
+		Model((*idempotencyKey)(nil)).
+		Set("status_code = ?", record.StatusCode).
+		Set("response_headers = ?", record.Header).
+		Set("response_body = ?", record.Body).
+		Set("expires_at = ?", time.Now().UTC().Add(ttl)).
+		Where("key = ?", key).
+		Exec(ctx)
+	return err
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	ctx, cancel := s.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.DB.NewDelete().
+		Model((*idempotencyKey)(nil)).
+		Where("key = ?", key).
+		Where("status_code IS NULL").
+		Exec(ctx)
+	return err
+}
+
+// Sweep deletes expired keys once per interval until Close.
+func (s *PostgresStore) Sweep(interval time.Duration, log logger.Logger) {
+	s.swept.Add(1)
+	go func() {
+		defer s.swept.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				n, err := s.deleteExpired()
+				if err != nil {
+					log.Warn("failed to delete expired idempotency keys", zap.Error(err))
+				}
+				if n > 0 {
+					log.Debug("deleted expired idempotency keys", zap.Int64("count", n))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the sweep and waits for a running one to finish.
+func (s *PostgresStore) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.swept.Wait()
+}
+
+// deleteExpired deletes expired keys in batches and returns how many it
+// deleted. Rows locked by a concurrent Reserve taking over the key are
+// skipped.
+func (s *PostgresStore) deleteExpired() (int64, error) {
+	var total int64
+	for {
+		select {
+		case <-s.stop:
+			return total, nil
+		default:
+		}
+
+		ctx, cancel := s.db.WithTimeout(context.Background())
+		expired := s.db.DB.NewSelect().
+			Model((*idempotencyKey)(nil)).
+			Column("key").
+			Where("expires_at < ?", time.Now().UTC()).
+			Limit(sweepBatchSize).
+			For("UPDATE SKIP LOCKED")
+		res, err := s.db.DB.NewDelete().
+			Model((*idempotencyKey)(nil)).
+			Where("key IN (?)", expired).
+			Exec(ctx)
+		cancel()
+		if err != nil {
+			return total, err
+		}
+
+		n, _ := res.RowsAffected()
+		total += n
+		if n < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "idempotency:"
+
+// redisRecord is the JSON value stored per key.
+type redisRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// RedisStore keeps idempotency keys in Redis and relies on key expiry for
+// cleanup.
+type RedisStore struct {
+	client *goredis.Client
+}
+
+// Compile-time interface check
+var _ router.IdempotencyStore = (*RedisStore)(nil)
+
+func NewRedisStore(client *goredis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*router.IdempotencyRecord, bool, error) {
+	pending, err := json.Marshal(redisRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := s.client.SetNX(ctx, redisKeyPrefix+key, pending, lockTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	raw, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			// The key expired in between; report it as busy and let the
+			// client retry.
+			return &router.IdempotencyRecord{Fingerprint: fingerprint}, false, nil
+		}
+		return nil, false, err
+	}
+	var rec redisRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, false, err
+	}
+	return &router.IdempotencyRecord{
+		Fingerprint: rec.Fingerprint,
+		StatusCode:  rec.StatusCode,
+		Header:      rec.Header,
+		Body:        rec.Body,
+	}, false, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, record *router.IdempotencyRecord, ttl time.Duration) error {
+	raw, err := json.Marshal(redisRecord{
+		Fingerprint: record.Fingerprint,
+		StatusCode:  record.StatusCode,
+		Header:      record.Header,
+		Body:        record.Body,
+	})
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, redisKeyPrefix+key, raw, ttl).Err()
+}
+
+// releaseScript deletes the key only while it is still pending, so a stored
+// response is never dropped.
+var releaseScript = goredis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value and not string.find(value, '"status_code"', 1, true) then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, s.client, []string{redisKeyPrefix + key}).Err()
+}
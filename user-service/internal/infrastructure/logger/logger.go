@@ -24,6 +24,9 @@ type Logger interface {
 	Panic(msg string, fields ...zap.Field)
 	With(fields ...zap.Field) Logger
 	Named(name string) Logger
+	// SetLevel changes the minimum level of this logger and every logger
+	// derived from the same root.
+	SetLevel(level string) error
 	Sync() error
 }
 
@@ -36,6 +39,7 @@ const (
 
 type zapLogger struct {
 	*zap.Logger
+	level zap.AtomicLevel
 }
 
 var _ Logger = (*zapLogger)(nil)
@@ -46,10 +50,11 @@ func NewLogger(config config.LogConfig) (Logger, error) {
 		return nil, fmt.Errorf("invalid log config: %w", err)
 	}
 
-	level, err := zapcore.ParseLevel(config.Level)
+	parsed, err := zapcore.ParseLevel(config.Level)
 	if err != nil {
 		return nil, fmt.Errorf("invalid log level %s: %w", config.Level, err)
 	}
+	level := zap.NewAtomicLevelAt(parsed)
 
 	encCfg := zapcore.EncoderConfig{
 		MessageKey:     "msg",
@@ -132,7 +137,7 @@ func NewLogger(config config.LogConfig) (Logger, error) {
 	}
 
 	logger := zap.New(core, opts...)
-	return &zapLogger{Logger: logger}, nil
+	return &zapLogger{Logger: logger, level: level}, nil
 }
 
 func createWriters(config config.LogConfig) ([]zapcore.WriteSyncer, error) {
@@ -174,11 +179,20 @@ func (l *zapLogger) Fatal(msg string, fields ...zap.Field) { l.Logger.Fatal(msg,
 func (l *zapLogger) Panic(msg string, fields ...zap.Field) { l.Logger.Panic(msg, fields...) }
 
 func (l *zapLogger) With(fields ...zap.Field) Logger {
-	return &zapLogger{Logger: l.Logger.With(fields...)}
+	return &zapLogger{Logger: l.Logger.With(fields...), level: l.level}
 }
 
 func (l *zapLogger) Named(name string) Logger {
-	return &zapLogger{Logger: l.Logger.Named(name)}
+	return &zapLogger{Logger: l.Logger.Named(name), level: l.level}
+}
+
+func (l *zapLogger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %s: %w", level, err)
+	}
+	l.level.SetLevel(parsed)
+	return nil
 }
 
 func (l *zapLogger) Sync() error {
@@ -401,3 +415,18 @@ func ProvideLogger(cfg *config.Config) (Logger, error) {
 		},
 	})
 }
+
+// ApplyReloads keeps the log level in line with the configuration and
+// reports reloads that were rejected. Other log settings need a restart.
+func ApplyReloads(m *config.Manager, log Logger) {
+	log = log.Named("config")
+	m.OnReload(func(cfg *config.Config) {
+		if err := log.SetLevel(cfg.Log.Level); err != nil {
+			log.Error("failed to apply log level", zap.Error(err))
+		}
+		log.Info("configuration reloaded", zap.String("log_level", cfg.Log.Level))
+	})
+	m.OnReloadError(func(err error) {
+		log.Error("configuration reload rejected, keeping the previous one", zap.Error(err))
+	})
+}
@@ -2,4 +2,8 @@ package logger
 
 import "go.uber.org/fx"
 
-var Module = fx.Module("logger", fx.Provide(ProvideLogger))
+var Module = fx.Module(
+	"logger",
+	fx.Provide(ProvideLogger),
+	fx.Invoke(ApplyReloads),
+)
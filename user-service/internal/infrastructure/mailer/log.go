@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LogMailer writes messages to a file or stdout instead of delivering them.
+// It is meant for local development.
+type LogMailer struct {
+	mu   sync.Mutex
+	out  io.Writer
+	from string
+}
+
+// NewLogMailer appends messages to path, or writes them to stdout when path
+// is empty.
+func NewLogMailer(path, from string) (*LogMailer, error) {
+	var out io.Writer = os.Stdout
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail log directory: %w", err)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mail log: %w", err)
+		}
+		out = f
+	}
+	return &LogMailer{out: out, from: from}, nil
+}
+
+func (m *LogMailer) Send(msg *Message) error {
+	raw, err := compose(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err = fmt.Fprintf(m.out, "----- mail -----\r\n%s\r\n----- end mail -----\r\n", raw)
+	return err
+}
@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// NewMailer returns the mailer selected by cfg.Driver.
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mail config: %w", err)
+	}
+	from := (&mail.Address{Name: cfg.FromName, Address: cfg.FromAddress}).String()
+
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTP, cfg.FromAddress, from), nil
+	default:
+		return NewLogMailer(cfg.LogPath, from)
+	}
+}
+
+func ProvideMailer(cfg *config.Config) (Mailer, error) {
+	return NewMailer(cfg.Mail)
+}
+
+// compose renders msg as an RFC 5322 message.
+func compose(from string, msg *Message) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("subject must not contain line breaks")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,17 @@
+package mailer
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"mailer",
+	fx.Provide(
+		ProvideMailer,
+		fx.Annotate(
+			NewNotifier,
+			fx.As(new(user.Notifier)),
+		),
+	),
+)
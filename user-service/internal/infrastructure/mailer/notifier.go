@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+)
+
+// Notifier renders the user domain's emails and hands them to a Mailer.
+type Notifier struct {
+	mailer    Mailer
+	appName   string
+	verifyURL string
+	resetURL  string
+}
+
+// Compile-time interface check
+var _ user.Notifier = (*Notifier)(nil)
+
+func NewNotifier(mailer Mailer, cfg *config.Config) *Notifier {
+	return &Notifier{
+		mailer:    mailer,
+		appName:   cfg.App.Name,
+		verifyURL: cfg.Auth.EmailVerification.URL,
+		resetURL:  cfg.Auth.PasswordReset.URL,
+	}
+}
+
+func (n *Notifier) SendEmailVerification(u *user.User, token string) error {
+	link, err := withToken(n.verifyURL, token)
+	if err != nil {
+		return err
+	}
+	return n.mailer.Send(&Message{
+		To:      u.Email,
+		Subject: fmt.Sprintf("Verify your email for %s", n.appName),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			u.Name, link,
+		),
+	})
+}
+
+func (n *Notifier) SendPasswordReset(u *user.User, token string) error {
+	link, err := withToken(n.resetURL, token)
+	if err != nil {
+		return err
+	}
+	return n.mailer.Send(&Message{
+		To:      u.Email,
+		Subject: fmt.Sprintf("Reset your %s password", n.appName),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below to choose a new one:\n\n%s\n\nThe link can be used once. If you did not ask for a reset, you can ignore this email.\n",
+			u.Name, link,
+		),
+	})
+}
+
+// withToken appends token to base as the token query parameter.
+func withToken(base, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil || base == "" {
+		return "", fmt.Errorf("invalid link url: %q", base)
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+	return link.String(), nil
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+)
+
+// SMTPMailer delivers messages through an SMTP server. STARTTLS is used when
+// the server offers it; credentials are only sent when configured.
+type SMTPMailer struct {
+	addr     string
+	auth     smtp.Auth
+	envelope string
+	from     string
+}
+
+// NewSMTPMailer sends as from, using envelope as the SMTP MAIL FROM address.
+func NewSMTPMailer(cfg config.SMTPMailConfig, envelope, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		auth:     auth,
+		envelope: envelope,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	raw, err := compose(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, raw); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+)
+
+// FileFlag is set while the file at app.maintenance.file exists; its
+// content, if any, is the message. Each instance reads its own file unless
+// the path is on a shared volume.
+type FileFlag struct {
+	manager *config.Manager
+}
+
+// Compile-time interface check
+var _ Flag = (*FileFlag)(nil)
+
+func NewFileFlag(manager *config.Manager) *FileFlag {
+	return &FileFlag{
+		manager: manager,
+	}
+}
+
+func (f *FileFlag) Get(_ context.Context) (bool, string, error) {
+	raw, err := os.ReadFile(f.manager.Current().App.Maintenance.File)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+	return true, strings.TrimSpace(string(raw)), nil
+}
@@ -0,0 +1,171 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// pollInterval is how often the flag is read. Requests are answered from the
+// last result, so they never wait for the flag's storage.
+const pollInterval = time.Second
+
+// Flag is the switch an operator sets to start maintenance at runtime.
+type Flag interface {
+	// Get reports whether the flag is set and the message stored with it.
+	Get(ctx context.Context) (bool, string, error)
+}
+
+// DriverFlag reads the flag of the driver currently configured in
+// app.maintenance.driver, so that changing the driver takes effect on reload
+// like the rest of the maintenance settings.
+type DriverFlag struct {
+	driver func() string
+	flags  map[string]Flag
+}
+
+// Compile-time interface check
+var _ Flag = (*DriverFlag)(nil)
+
+func NewDriverFlag(driver func() string, flags map[string]Flag) *DriverFlag {
+	return &DriverFlag{
+		driver: driver,
+		flags:  flags,
+	}
+}
+
+func (f *DriverFlag) Get(ctx context.Context) (bool, string, error) {
+	driver := f.driver()
+	flag, ok := f.flags[driver]
+	if !ok {
+		return false, "", fmt.Errorf("unknown maintenance driver: %s", driver)
+	}
+	return flag.Get(ctx)
+}
+
+// Source implements router.MaintenanceSource by polling the flag and the
+// current configuration, so that both the flag and config reloads take
+// effect within a poll interval.
+type Source struct {
+	manager *config.Manager
+	flag    Flag
+	logger  logger.Logger
+	state   atomic.Pointer[router.MaintenanceState]
+	// flagSet and flagMessage are the flag's last known value. Only the
+	// polling goroutine uses them.
+	flagSet     bool
+	flagMessage string
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// Compile-time interface check
+var _ router.MaintenanceSource = (*Source)(nil)
+
+func NewSource(manager *config.Manager, flag Flag, log logger.Logger) *Source {
+	s := &Source{
+		manager: manager,
+		flag:    flag,
+		logger:  log.Named("maintenance"),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.state.Store(&router.MaintenanceState{})
+	return s
+}
+
+func (s *Source) Maintenance() router.MaintenanceState {
+	return *s.state.Load()
+}
+
+// Start reads the flag once and then keeps polling it until Stop.
+func (s *Source) Start() {
+	s.poll()
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.poll()
+			}
+		}
+	}()
+}
+
+func (s *Source) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Source) poll() {
+	cfg := s.manager.Current().App.Maintenance
+	next := &router.MaintenanceState{
+		Enabled:    cfg.Enabled,
+		Message:    cfg.Message,
+		RetryAfter: cfg.RetryAfter,
+	}
+	// Validated when the configuration was loaded
+	next.Allowed, _ = cfg.AllowedPrefixes()
+
+	ctx, cancel := context.WithTimeout(context.Background(), pollInterval)
+	defer cancel()
+	set, message, err := s.flag.Get(ctx)
+	if err != nil {
+		// Keep the last known value rather than opening or closing the
+		// service because the flag's storage is unavailable
+		s.logger.Error("maintenance flag check failed", zap.Error(err))
+		set, message = s.flagSet, s.flagMessage
+	}
+	s.flagSet, s.flagMessage = set, message
+	if set {
+		next.Enabled = true
+		if message != "" {
+			next.Message = message
+		}
+	}
+
+	if prev := s.state.Swap(next); prev.Enabled != next.Enabled {
+		if next.Enabled {
+			s.logger.Warn("maintenance mode on", zap.String("message", next.Message))
+		} else {
+			s.logger.Info("maintenance mode off")
+		}
+	}
+}
+
+// ProvideSource polls the flag of the driver configured in
+// app.maintenance.driver while the application runs.
+func ProvideSource(lc fx.Lifecycle, manager *config.Manager, client *goredis.Client, log logger.Logger) router.MaintenanceSource {
+	flag := NewDriverFlag(
+		func() string { return manager.Current().App.Maintenance.Driver },
+		map[string]Flag{
+			"file":  NewFileFlag(manager),
+			"redis": NewRedisFlag(client),
+		},
+	)
+
+	source := NewSource(manager, flag, log)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			source.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			source.Stop()
+			return nil
+		},
+	})
+	return source
+}
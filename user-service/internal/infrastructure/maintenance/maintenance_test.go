@@ -0,0 +1,40 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+)
+
+// staticFlag is always in the same state.
+type staticFlag struct {
+	set     bool
+	message string
+}
+
+func (f staticFlag) Get(context.Context) (bool, string, error) {
+	return f.set, f.message, nil
+}
+
+func TestDriverFlagFollowsConfiguredDriver(t *testing.T) {
+	driver := "file"
+	flag := NewDriverFlag(func() string { return driver }, map[string]Flag{
+		"file":  staticFlag{},
+		"redis": staticFlag{set: true, message: "Back at 10:00"},
+	})
+
+	if set, _, err := flag.Get(context.Background()); err != nil || set {
+		t.Fatalf("file flag = %v, %v; want unset", set, err)
+	}
+
+	// A config reload switches the driver
+	driver = "redis"
+	set, message, err := flag.Get(context.Background())
+	if err != nil || !set || message != "Back at 10:00" {
+		t.Errorf("flag after switching to redis = %v, %q, %v; want the redis flag", set, message, err)
+	}
+
+	driver = "consul"
+	if _, _, err := flag.Get(context.Background()); err == nil {
+		t.Error("unknown driver did not fail")
+	}
+}
@@ -0,0 +1,14 @@
+package maintenance
+
+import (
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"maintenance",
+	fx.Provide(
+		ProvideSource,
+		router.NewMaintenanceMiddleware,
+	),
+)
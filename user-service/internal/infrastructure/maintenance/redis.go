@@ -0,0 +1,37 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisKey is set while the service is down for maintenance; its value, if
+// any, is the message. All instances share it.
+const redisKey = "maintenance"
+
+type RedisFlag struct {
+	client *goredis.Client
+}
+
+// Compile-time interface check
+var _ Flag = (*RedisFlag)(nil)
+
+func NewRedisFlag(client *goredis.Client) *RedisFlag {
+	return &RedisFlag{
+		client: client,
+	}
+}
+
+func (f *RedisFlag) Get(ctx context.Context) (bool, string, error) {
+	message, err := f.client.Get(ctx, redisKey).Result()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+	return true, strings.TrimSpace(message), nil
+}
@@ -0,0 +1,175 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// clockSkew is tolerated when checking the id_token's time claims.
+	clockSkew = time.Minute
+)
+
+// idTokenMethods are the signing algorithms accepted for id_tokens. Symmetric
+// algorithms are excluded since the client secret is not a provider key.
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Client performs the authorization-code flow with PKCE against a single
+// provider. The profile is read from the userinfo endpoint when configured
+// and from the verified id_token returned by the token endpoint otherwise.
+type Client struct {
+	cfg         *oauth2.Config
+	userInfoURL string
+	issuer      string
+	keys        *keySet
+	httpClient  *http.Client
+}
+
+// Compile-time interface check
+var _ auth.OAuthClient = (*Client)(nil)
+
+func NewClient(p config.OAuthProvider, httpClient *http.Client) (*Client, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &Client{
+		cfg: &oauth2.Config{
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  p.AuthURL,
+				TokenURL: p.TokenURL,
+			},
+		},
+		userInfoURL: p.UserInfoURL,
+		issuer:      p.Issuer,
+		keys:        newKeySet(p.JWKSURL, httpClient),
+		httpClient:  httpClient,
+	}, nil
+}
+
+func (c *Client) AuthCodeURL(state, verifier, nonce string) string {
+	return c.cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce))
+}
+
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*auth.ExternalProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
+
+	tok, err := c.cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	var claims profileClaims
+	if c.userInfoURL != "" {
+		err = c.fetchUserInfo(ctx, tok, &claims)
+	} else {
+		err = c.verifyIDToken(ctx, tok, nonce, &claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return claims.profile()
+}
+
+func (c *Client) fetchUserInfo(ctx context.Context, tok *oauth2.Token, claims *profileClaims) error {
+	resp, err := c.cfg.Client(ctx, tok).Get(c.userInfoURL)
+	if err != nil {
+		return fmt.Errorf("userinfo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("userinfo returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(claims); err != nil {
+		return fmt.Errorf("failed to decode userinfo: %w", err)
+	}
+	return nil
+}
+
+// verifyIDToken reads the profile from the id_token after validating it as
+// OpenID Connect Core 3.1.3.7 requires: signed with one of the provider's
+// published keys, issued by the provider for this client, unexpired, and
+// bound to this authorization request by its nonce.
+func (c *Client) verifyIDToken(ctx context.Context, tok *oauth2.Token, nonce string, claims *profileClaims) error {
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return errors.New("token response has no id_token")
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(c.issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return fmt.Errorf("invalid id_token: %w", err)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return errors.New("invalid id_token: nonce does not match")
+	}
+	return nil
+}
+
+// profileClaims covers the OIDC standard claims plus the "id" field used by
+// providers such as Facebook that are not OIDC compliant.
+type profileClaims struct {
+	Subject       string `json:"sub"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+func (c *profileClaims) profile() (*auth.ExternalProfile, error) {
+	subject := c.Subject
+	if subject == "" {
+		subject = c.ID
+	}
+	if subject == "" {
+		return nil, errors.New("provider returned no subject")
+	}
+
+	// Some providers (Apple) encode email_verified as a string
+	verified := false
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = strings.EqualFold(v, "true")
+	}
+
+	return &auth.ExternalProfile{
+		Subject:       subject,
+		Email:         strings.ToLower(c.Email),
+		EmailVerified: verified,
+		Name:          c.Name,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://issuer.test"
+	testClientID = "client-id"
+	testVerifier = "verifier-verifier-verifier-verifier-verifier"
+	testNonce    = "nonce"
+)
+
+// testProvider is an OIDC provider serving a token endpoint that returns
+// idToken and a JWKS endpoint publishing key under kid "k1".
+type testProvider struct {
+	*httptest.Server
+	key         *rsa.PrivateKey
+	idToken     string
+	jwksFetches int
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	p := &testProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code_verifier") != testVerifier {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     p.idToken,
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksFetches++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) client(t *testing.T) *Client {
+	t.Helper()
+	c, err := NewClient(config.OAuthProvider{
+		ClientID:    testClientID,
+		RedirectURL: "https://app.test/callback",
+		AuthURL:     p.URL + "/authorize",
+		TokenURL:    p.URL + "/token",
+		Issuer:      testIssuer,
+		JWKSURL:     p.URL + "/jwks",
+	}, p.Client())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+func signIDToken(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = kid
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing id_token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            testIssuer,
+		"aud":            testClientID,
+		"sub":            "subject",
+		"email":          "Jane@Example.com",
+		"email_verified": "true",
+		"nonce":          testNonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value any) jwt.MapClaims {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestExchangeVerifiesIDToken(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tests := []struct {
+		name    string
+		idToken string
+		wantErr bool
+	}{
+		{"valid", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", validClaims()), false},
+		{"other issuer", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("iss", "https://evil.test")), true},
+		{"other audience", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("aud", "other-client")), true},
+		{"expired", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("exp", time.Now().Add(-time.Hour).Unix())), true},
+		{"no expiry", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("exp", nil)), true},
+		{"other nonce", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("nonce", "replayed")), true},
+		{"no nonce", signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", withClaim("nonce", nil)), true},
+		{"signed with another key", signIDToken(t, jwt.SigningMethodRS256, otherKey, "k1", validClaims()), true},
+		{"unknown key id", signIDToken(t, jwt.SigningMethodRS256, p.key, "k2", validClaims()), true},
+		{"signed with the client id as hmac secret", signIDToken(t, jwt.SigningMethodHS256, []byte(testClientID), "k1", validClaims()), true},
+		{"alg none", unsigned, true},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.idToken = tt.idToken
+			profile, err := c.Exchange(context.Background(), "code", testVerifier, testNonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Exchange = %+v, want an error", profile)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if profile.Subject != "subject" || profile.Email != "jane@example.com" || !profile.EmailVerified {
+				t.Errorf("profile = %+v", profile)
+			}
+		})
+	}
+}
+
+func TestExchangeSendsCodeVerifier(t *testing.T) {
+	p := newTestProvider(t)
+	p.idToken = signIDToken(t, jwt.SigningMethodRS256, p.key, "k1", validClaims())
+
+	if _, err := p.client(t).Exchange(context.Background(), "code", "other-verifier", testNonce); err == nil {
+		t.Error("Exchange with the wrong code verifier succeeded")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p := newTestProvider(t)
+	u, err := url.Parse(p.client(t).AuthCodeURL("state", testVerifier, testNonce))
+	if err != nil {
+		t.Fatalf("AuthCodeURL is not a URL: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(testVerifier))
+	want := map[string]string{
+		"state":                 "state",
+		"nonce":                 testNonce,
+		"client_id":             testClientID,
+		"response_type":         "code",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+	}
+	q := u.Query()
+	for name, value := range want {
+		if got := q.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if q.Has("code_verifier") {
+		t.Error("the code verifier is sent in the authorization URL")
+	}
+}
+
+// Tokens naming unknown keys must not trigger a JWKS fetch each.
+func TestUnknownKeyRefetchIsLimited(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client(t)
+
+	for range 3 {
+		p.idToken = signIDToken(t, jwt.SigningMethodRS256, p.key, "unknown", validClaims())
+		if _, err := c.Exchange(context.Background(), "code", testVerifier, testNonce); err == nil {
+			t.Fatal("Exchange with an unknown key succeeded")
+		}
+	}
+	if p.jwksFetches != 1 {
+		t.Errorf("jwks fetched %d times, want 1", p.jwksFetches)
+	}
+}
@@ -0,0 +1,16 @@
+package oauth
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"oauth",
+	fx.Provide(
+		fx.Annotate(
+			ProvideRegistry,
+			fx.As(new(auth.OAuthProviders)),
+		),
+	),
+)
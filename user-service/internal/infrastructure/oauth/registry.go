@@ -0,0 +1,41 @@
+package oauth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+)
+
+// Registry holds a client for every enabled provider.
+type Registry struct {
+	clients map[string]*Client
+}
+
+// Compile-time interface check
+var _ auth.OAuthProviders = (*Registry)(nil)
+
+func NewRegistry(cfg config.OAuthConfig) (*Registry, error) {
+	httpClient := &http.Client{Timeout: requestTimeout}
+
+	clients := map[string]*Client{}
+	for name, p := range cfg.Providers() {
+		c, err := NewClient(p, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid oauth provider %s: %w", name, err)
+		}
+		clients[name] = c
+	}
+	return &Registry{clients: clients}, nil
+}
+
+func (r *Registry) Provider(name string) (auth.OAuthClient, bool) {
+	c, ok := r.clients[name]
+	return c, ok
+}
+
+// ProvideRegistry provides the OAuth provider registry for dependency injection.
+func ProvideRegistry(cfg *config.Config) (*Registry, error) {
+	return NewRegistry(cfg.Auth.OAuth)
+}
@@ -0,0 +1,16 @@
+package otp
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"otp",
+	fx.Provide(
+		fx.Annotate(
+			ProvideTOTP,
+			fx.As(new(user.TOTPProvider)),
+		),
+	),
+)
@@ -0,0 +1,158 @@
+package otp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+)
+
+const (
+	// period is the RFC 6238 time step.
+	period = 30 * time.Second
+	// skew is the number of steps accepted on either side of the current one
+	// to tolerate clock drift between server and authenticator.
+	skew = 1
+
+	secretBytes = 20
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP implements RFC 6238 time-based one-time passwords using HMAC-SHA1,
+// which is what common authenticator apps support. Secrets are sealed with
+// AES-GCM using a key derived from the configured OTP secret.
+type TOTP struct {
+	issuer string
+	digits int
+	aead   cipher.AEAD
+	now    func() time.Time
+}
+
+// Compile-time interface check
+var _ user.TOTPProvider = (*TOTP)(nil)
+
+func NewTOTP(cfg config.OTPConfig, issuer string) (*TOTP, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid otp config: %w", err)
+	}
+
+	key := sha256.Sum256([]byte(cfg.Secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	return &TOTP{
+		issuer: issuer,
+		digits: cfg.Length,
+		aead:   aead,
+		now:    time.Now,
+	}, nil
+}
+
+func (t *TOTP) GenerateSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return b32.EncodeToString(buf), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI rendered as a QR code by authenticator apps.
+func (t *TOTP) ProvisioningURI(secret, account string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", t.issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(t.digits))
+	q.Set("period", fmt.Sprint(int(period.Seconds())))
+
+	label := url.PathEscape(t.issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Validate checks code against the steps around the current time and returns
+// the matching step so callers can reject replays of the same code.
+func (t *TOTP) Validate(secret, code string) (int64, bool) {
+	key, err := b32.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != t.digits {
+		return 0, false
+	}
+
+	current := t.now().Unix() / int64(period.Seconds())
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		if subtle.ConstantTimeCompare([]byte(t.code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func (t *TOTP) Seal(secret string) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := t.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (t *TOTP) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	size := t.aead.NonceSize()
+	if len(raw) < size {
+		return "", errors.New("sealed secret is too short")
+	}
+	plain, err := t.aead.Open(nil, raw[:size], raw[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// code computes the HOTP value (RFC 4226) for the given counter.
+func (t *TOTP) code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range t.digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.digits, value%mod)
+}
+
+// ProvideTOTP provides a TOTP provider for dependency injection.
+func ProvideTOTP(cfg *config.Config) (*TOTP, error) {
+	return NewTOTP(cfg.Auth.OTP, cfg.App.Name)
+}
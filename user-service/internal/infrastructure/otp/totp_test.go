@@ -0,0 +1,150 @@
+package otp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+)
+
+func newTestTOTP(t *testing.T, length int, secret string) *TOTP {
+	t.Helper()
+	totp, err := NewTOTP(config.OTPConfig{Secret: secret, Length: length, ExpiresIn: time.Minute}, "Example")
+	if err != nil {
+		t.Fatalf("NewTOTP: %v", err)
+	}
+	return totp
+}
+
+// The SHA1 test vectors from RFC 6238, Appendix B.
+func TestValidateRFC6238Vectors(t *testing.T) {
+	secret := b32.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			totp := newTestTOTP(t, 8, "key")
+			totp.now = func() time.Time { return time.Unix(tt.unix, 0) }
+
+			step, ok := totp.Validate(secret, tt.code)
+			if !ok || step != tt.unix/30 {
+				t.Errorf("Validate = %d, %v; want %d, true", step, ok, tt.unix/30)
+			}
+		})
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	totp := newTestTOTP(t, 6, "key")
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, _ := b32.DecodeString(secret)
+	now := time.Unix(1_700_000_000, 0)
+	totp.now = func() time.Time { return now }
+	current := now.Unix() / 30
+
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"current step", totp.code(key, current), true},
+		{"previous step", totp.code(key, current-1), true},
+		{"next step", totp.code(key, current+1), true},
+		{"two steps old", totp.code(key, current-2), false},
+		{"two steps ahead", totp.code(key, current+2), false},
+		{"too short", totp.code(key, current)[:5], false},
+		{"too long", totp.code(key, current) + "0", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := totp.Validate(secret, tt.code); ok != tt.want {
+				t.Errorf("Validate = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := totp.Validate("not base32!", totp.code(key, current)); ok {
+		t.Error("Validate with a malformed secret succeeded")
+	}
+	if _, ok := totp.Validate(strings.ToLower(secret), totp.code(key, current)); !ok {
+		t.Error("Validate with a lower-case secret failed")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	totp := newTestTOTP(t, 6, "key")
+	a, _ := totp.GenerateSecret()
+	b, _ := totp.GenerateSecret()
+	if a == b {
+		t.Error("two generated secrets are equal")
+	}
+	raw, err := b32.DecodeString(a)
+	if err != nil || len(raw) != secretBytes {
+		t.Errorf("secret decodes to %d bytes, %v; want %d bytes", len(raw), err, secretBytes)
+	}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	totp := newTestTOTP(t, 6, "key")
+	sealed, err := totp.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Error("sealed secret contains the plain secret")
+	}
+	if again, _ := totp.Seal("JBSWY3DPEHPK3PXP"); again == sealed {
+		t.Error("sealing twice gave the same ciphertext")
+	}
+	if plain, err := totp.Open(sealed); err != nil || plain != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Open = %q, %v; want the sealed secret", plain, err)
+	}
+
+	tests := []struct {
+		name   string
+		totp   *TOTP
+		sealed string
+	}{
+		{"other key", newTestTOTP(t, 6, "other key"), sealed},
+		{"tampered", totp, sealed[:len(sealed)-4] + "AAAA"},
+		{"too short", totp, "AAAA"},
+		{"not base64", totp, "!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.totp.Open(tt.sealed); err == nil {
+				t.Error("Open succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	totp := newTestTOTP(t, 6, "key")
+	uri, err := url.Parse(totp.ProvisioningURI("JBSWY3DPEHPK3PXP", "jane@example.com"))
+	if err != nil {
+		t.Fatalf("ProvisioningURI is not a URL: %v", err)
+	}
+	q := uri.Query()
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Example:jane@example.com" {
+		t.Errorf("URI = %s, want otpauth://totp/Example:jane@example.com", uri)
+	}
+	if q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "Example" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("query = %v", q)
+	}
+}
@@ -0,0 +1,10 @@
+package redis
+
+import "go.uber.org/fx"
+
+var Module = fx.Module(
+	"redis",
+	fx.Provide(
+		ProvideClient,
+	),
+)
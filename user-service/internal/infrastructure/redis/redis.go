@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// NewClient returns a client for cfg. Connections are opened lazily, so
+// providing a client costs nothing when no feature is configured to use Redis.
+func NewClient(cfg config.RedisConfig) *goredis.Client {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = cfg.Default
+	}
+	return goredis.NewClient(&goredis.Options{
+		Addr:     addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
+
+func ProvideClient(lc fx.Lifecycle, cfg *config.Config) *goredis.Client {
+	client := NewClient(cfg.Redis)
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+	return client
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/uptrace/bun"
+)
+
+type AuditRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ audit.AuditRepository = (*AuditRepositoryImpl)(nil)
+
+func NewAuditRepository(db *database.Database) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *AuditRepositoryImpl) ListEntries(ctx context.Context, filter *audit.Filter) (*[]audit.Entry, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var entries []audit.Entry
+	q := r.db.Reader(ctx).NewSelect().Model(&entries)
+	if filter.UserID != nil {
+		q = q.Where("al.user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		q = q.Where("al.actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != nil {
+		q = q.Where("al.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("al.created_at < ?", *filter.To)
+	}
+	if filter.After != nil {
+		q = q.Where("(al.created_at, al.id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	err := q.
+		OrderExpr("al.created_at DESC, al.id DESC").
+		Limit(filter.Limit + 1).
+		Scan(ctx)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return &entries, nil
+}
+
+// writeAudit appends entry to the audit log. Callers pass the transaction
+// that makes the audited change so both are committed together.
+func writeAudit(ctx context.Context, db bun.IDB, entry *audit.Entry) error {
+	_, err := db.NewInsert().Model(entry).Returning("id").Exec(ctx)
+	return err
+}
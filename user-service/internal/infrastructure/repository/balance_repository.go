@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// serializableRetries bounds how often a posting is retried after Postgres
+// aborts it with a serialization failure.
+const serializableRetries = 3
+
+var errInsufficientFunds = shared.NewDomainError("INSUFFICIENT_FUNDS", 422, "insufficient balance")
+
+type BalanceRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ balance.BalanceRepository = (*BalanceRepositoryImpl)(nil)
+
+func NewBalanceRepository(db *database.Database) *BalanceRepositoryImpl {
+	return &BalanceRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *BalanceRepositoryImpl) GetAccount(ctx context.Context, userID uuid.UUID) (*balance.Account, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	account := new(balance.Account)
+	err := r.db.DB.NewSelect().Model(account).Where("b.user_id = ?", userID).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &balance.Account{UserID: &userID}, nil
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return account, nil
+}
+
+func (r *BalanceRepositoryImpl) Post(ctx context.Context, userID uuid.UUID, amount int64, txn *balance.Transaction, actor audit.Actor) (*balance.Entry, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var (
+		entry     *balance.Entry
+		domainErr *shared.DomainError
+		err       error
+	)
+	for attempt := 0; attempt < serializableRetries; attempt++ {
+		entry, domainErr = nil, nil
+		err = r.db.DB.RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(ctx context.Context, tx bun.Tx) error {
+			var postErr error
+			entry, postErr = post(ctx, tx, userID, amount, txn)
+			if errors.Is(postErr, errInsufficientFunds) {
+				domainErr = errInsufficientFunds
+			}
+			if postErr != nil {
+				return postErr
+			}
+			action := audit.ActionBalanceCredited
+			if amount < 0 {
+				action = audit.ActionBalanceDebited
+			}
+			return writeAudit(ctx, tx, actor.Entry(userID, action, map[string]any{
+				"amount":         amount,
+				"balance_after":  *entry.BalanceAfter,
+				"transaction_id": txn.ID,
+				"reference":      txn.Reference,
+			}))
+		})
+		if !isSerializationFailure(err) {
+			break
+		}
+	}
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, mapDBError(err, "POSTING_FAILED")
+	}
+	return entry, nil
+}
+
+// post writes a balanced transaction: the user's entry and the opposite entry
+// on the external account.
+func post(ctx context.Context, tx bun.Tx, userID uuid.UUID, amount int64, txn *balance.Transaction) (*balance.Entry, error) {
+	_, err := tx.NewInsert().
+		Model(&balance.Account{UserID: &userID}).
+		On("CONFLICT (user_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := new(balance.Account)
+	err = tx.NewUpdate().
+		Model(account).
+		Set("amount = b.amount + ?", amount).
+		Set("updated_at = ?", time.Now().UTC()).
+		Where("b.user_id = ?", userID).
+		Where("b.amount + ? >= 0", amount).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInsufficientFunds
+		}
+		return nil, err
+	}
+
+	var externalID uuid.UUID
+	err = tx.NewSelect().
+		Model((*balance.Account)(nil)).
+		Column("id").
+		Where("b.system_code = ?", balance.ExternalAccount).
+		Scan(ctx, &externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.NewInsert().Model(txn).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	entries := []balance.Entry{
+		{TransactionID: txn.ID, AccountID: account.ID, Amount: amount, BalanceAfter: &account.Amount},
+		{TransactionID: txn.ID, AccountID: externalID, Amount: -amount},
+	}
+	if _, err := tx.NewInsert().Model(&entries).Returning("*").Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	entry := &entries[0]
+	entry.Transaction = txn
+	return entry, nil
+}
+
+// ListEntries returns a page of the user's entries, newest first. It fetches
+// filter.Limit+1 rows so the caller can tell whether another page exists.
+func (r *BalanceRepositoryImpl) ListEntries(ctx context.Context, userID uuid.UUID, filter *balance.EntryFilter) (*[]balance.Entry, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var entries []balance.Entry
+	q := r.db.DB.NewSelect().
+		Model(&entries).
+		Relation("Transaction").
+		Join("JOIN balance AS b ON b.id = le.account_id").
+		Where("b.user_id = ?", userID)
+	if filter.After != nil {
+		q = q.Where("(le.created_at, le.id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	err := q.
+		OrderExpr("le.created_at DESC, le.id DESC").
+		Limit(filter.Limit + 1).
+		Scan(ctx)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return &entries, nil
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr pgdriver.Error
+	return errors.As(err, &pgErr) && pgErr.Field('C') == pgSerializationFailure
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// Postgres SQLSTATE codes translated by mapDBError.
+const (
+	pgUniqueViolation       = "23505"
+	pgForeignKeyViolation   = "23503"
+	pgNotNullViolation      = "23502"
+	pgCheckViolation        = "23514"
+	pgStringTooLong         = "22001"
+	pgInvalidTextRepr       = "22P02"
+	pgSerializationFailure  = "40001"
+	pgDeadlockDetected      = "40P01"
+	pgQueryCanceled         = "57014"
+	pgTooManyConnections    = "53300"
+	pgConnectionExceptClass = "08"
+)
+
+// uniqueConstraintErrors gives unique violations on known constraints a
+// specific code so clients can tell which value is taken.
+var uniqueConstraintErrors = map[string]*shared.DomainError{
+	"users_email_key":                     shared.NewDomainError("EMAIL_TAKEN", 409, "email is already taken"),
+	"uq_user_identities_provider_subject": shared.NewDomainError("IDENTITY_ALREADY_LINKED", 409, "identity is already linked to an account"),
+	"uq_ledger_transactions_reference":    shared.NewDomainError("DUPLICATE_REFERENCE", 409, "a transaction with this reference already exists"),
+}
+
+// foreignKeyConstraintErrors does the same for foreign keys that restrict
+// deletes, where the generic message would be misleading.
+var foreignKeyConstraintErrors = map[string]*shared.DomainError{
+	"fk_ledger_entries_account": shared.NewDomainError("LEDGER_HISTORY_EXISTS", 409, "accounts with balance history cannot be deleted"),
+}
+
+// mapDBError translates a database error into a DomainError with a stable
+// code. Errors that are not recognized are reported under fallbackCode with
+// status 500. The driver message is never exposed to clients; it is kept as
+// the cause for logging.
+func mapDBError(err error, fallbackCode string) *shared.DomainError {
+	var derr *shared.DomainError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		derr = shared.NewDomainError("DB_TIMEOUT", 504, "database operation timed out")
+	case errors.Is(err, context.Canceled):
+		derr = shared.NewDomainError("REQUEST_CANCELED", 499, "request was canceled")
+	default:
+		derr = mapPGError(err)
+	}
+	if derr == nil {
+		derr = shared.NewDomainError(fallbackCode, 500, "internal database error")
+	}
+	return derr.WithCause(err)
+}
+
+func mapPGError(err error) *shared.DomainError {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		var netErr net.Error
+		if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+			return shared.NewDomainError("DB_UNAVAILABLE", 503, "database is unavailable")
+		}
+		return nil
+	}
+
+	switch code := pgErr.Field('C'); {
+	case code == pgUniqueViolation:
+		if known, ok := uniqueConstraintErrors[pgErr.Field('n')]; ok {
+			return shared.NewDomainError(known.Code, known.StatusCode, known.Message)
+		}
+		return shared.NewDomainError("ALREADY_EXISTS", 409, "resource already exists")
+	case code == pgForeignKeyViolation:
+		if known, ok := foreignKeyConstraintErrors[pgErr.Field('n')]; ok {
+			return shared.NewDomainError(known.Code, known.StatusCode, known.Message)
+		}
+		return shared.NewDomainError("REFERENCE_NOT_FOUND", 422, "referenced resource does not exist")
+	case code == pgNotNullViolation:
+		return shared.NewDomainError("MISSING_VALUE", 422, "a required value is missing")
+	case code == pgCheckViolation:
+		return shared.NewDomainError("CONSTRAINT_VIOLATION", 422, "a value violates a constraint")
+	case code == pgStringTooLong:
+		return shared.NewDomainError("VALUE_TOO_LONG", 422, "a value is too long")
+	case code == pgInvalidTextRepr:
+		return shared.NewDomainError("INVALID_VALUE", 400, "a value has an invalid format")
+	case code == pgSerializationFailure, code == pgDeadlockDetected:
+		return shared.NewDomainError("CONCURRENT_UPDATE", 409, "the resource was modified concurrently, retry the request")
+	case code == pgQueryCanceled:
+		return shared.NewDomainError("DB_TIMEOUT", 504, "database operation timed out")
+	case code == pgTooManyConnections, strings.HasPrefix(code, pgConnectionExceptClass):
+		return shared.NewDomainError("DB_UNAVAILABLE", 503, "database is unavailable")
+	}
+	return nil
+}
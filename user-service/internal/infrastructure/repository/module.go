@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
 	"go.uber.org/fx"
 )
@@ -13,5 +17,35 @@ var Module = fx.Module(
 			NewUserRepository,
 			fx.As(new(user.UserRepository)),
 		),
+		NewRefreshTokenRepository,
+		fx.Annotate(
+			NewRefreshTokenRepository,
+			fx.As(new(auth.RefreshTokenRepository)),
+		),
+		NewSessionRepository,
+		fx.Annotate(
+			NewSessionRepository,
+			fx.As(new(auth.SessionRepository)),
+		),
+		NewOAuthRepository,
+		fx.Annotate(
+			NewOAuthRepository,
+			fx.As(new(auth.OAuthRepository)),
+		),
+		NewBalanceRepository,
+		fx.Annotate(
+			NewBalanceRepository,
+			fx.As(new(balance.BalanceRepository)),
+		),
+		NewAuditRepository,
+		fx.Annotate(
+			NewAuditRepository,
+			fx.As(new(audit.AuditRepository)),
+		),
+		NewRoleRepository,
+		fx.Annotate(
+			NewRoleRepository,
+			fx.As(new(rbac.RoleRepository)),
+		),
 	),
 )
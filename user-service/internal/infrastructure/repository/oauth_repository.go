@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/uptrace/bun"
+)
+
+type OAuthRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ auth.OAuthRepository = (*OAuthRepositoryImpl)(nil)
+
+func NewOAuthRepository(db *database.Database) *OAuthRepositoryImpl {
+	return &OAuthRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *OAuthRepositoryImpl) CreateState(ctx context.Context, state *auth.OAuthState) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Opportunistically purge abandoned authorization requests
+		_, err := tx.NewDelete().
+			Model((*auth.OAuthState)(nil)).
+			Where("expires_at < ?", time.Now().UTC()).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewInsert().Model(state).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return mapDBError(err, "CREATE_FAILED")
+	}
+	return nil
+}
+
+func (r *OAuthRepositoryImpl) ConsumeState(ctx context.Context, stateHash string) (*auth.OAuthState, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	state := new(auth.OAuthState)
+	err := r.db.DB.NewDelete().
+		Model(state).
+		Where("state_hash = ?", stateHash).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, shared.NewDomainError("INVALID_OAUTH_STATE", 400, "oauth state is invalid or was already used")
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return state, nil
+}
+
+func (r *OAuthRepositoryImpl) GetIdentity(ctx context.Context, provider, subject string) (*auth.Identity, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	identity := new(auth.Identity)
+	err := r.db.DB.NewSelect().
+		Model(identity).
+		Where("provider = ?", provider).
+		Where("subject = ?", subject).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, shared.NewDomainError("IDENTITY_NOT_FOUND", 404, "identity not found")
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return identity, nil
+}
+
+func (r *OAuthRepositoryImpl) CreateUserWithIdentity(ctx context.Context, u *user.User, identity *auth.Identity, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(u).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		identity.UserID = u.ID
+		if _, err := tx.NewInsert().Model(identity).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		return writeAudit(ctx, tx, actor.Entry(u.ID, audit.ActionUserCreated, map[string]any{
+			"email":    u.Email,
+			"provider": identity.Provider,
+		}))
+	})
+	if err != nil {
+		return mapDBError(err, "CREATE_FAILED")
+	}
+	return nil
+}
+
+func (r *OAuthRepositoryImpl) LinkIdentity(ctx context.Context, identity *auth.Identity, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(identity).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		return writeAudit(ctx, tx, actor.Entry(identity.UserID, audit.ActionIdentityLinked, map[string]any{
+			"provider": identity.Provider,
+		}))
+	})
+	if err != nil {
+		return mapDBError(err, "CREATE_FAILED")
+	}
+	return nil
+}
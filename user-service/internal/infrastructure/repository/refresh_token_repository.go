@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var (
+	errRefreshTokenInvalid = shared.NewDomainError("INVALID_REFRESH_TOKEN", 401, "refresh token is invalid")
+	errRefreshTokenExpired = shared.NewDomainError("REFRESH_TOKEN_EXPIRED", 401, "refresh token has expired")
+	errRefreshTokenReused  = shared.NewDomainError("REFRESH_TOKEN_REUSED", 401, "refresh token has already been used")
+)
+
+type RefreshTokenRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ auth.RefreshTokenRepository = (*RefreshTokenRepositoryImpl)(nil)
+
+func NewRefreshTokenRepository(db *database.Database) *RefreshTokenRepositoryImpl {
+	return &RefreshTokenRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *RefreshTokenRepositoryImpl) RotateRefreshToken(ctx context.Context, hash string, next *auth.RefreshToken, ip string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var domainErr *shared.DomainError
+	err := r.db.DB.RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}, func(ctx context.Context, tx bun.Tx) error {
+		current := new(auth.RefreshToken)
+		// Lock the row so concurrent refreshes with the same token serialize
+		err := tx.NewSelect().Model(current).Where("token_hash = ?", hash).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				domainErr = errRefreshTokenInvalid
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now().UTC()
+		if domainErr = checkRotation(current, now); domainErr != nil {
+			if domainErr == errRefreshTokenReused {
+				// A rotated token was replayed: assume it was stolen and
+				// revoke every token descending from the same login.
+				return revokeFamily(ctx, tx, current.FamilyID, now)
+			}
+			return nil
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*auth.RefreshToken)(nil)).
+			Set("rotated_at = ?", now).
+			Where("id = ?", current.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		next.UserID = current.UserID
+		next.FamilyID = current.FamilyID
+		_, err = tx.NewInsert().Model(next).Returning("id").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*auth.Session)(nil)).
+			Set("expires_at = ?", next.ExpiresAt).
+			Set("last_seen_at = ?", now).
+			// The client's address may have changed since the last refresh
+			Set("ip = COALESCE(NULLIF(?, ''), ip)", ip).
+			Where("id = ?", current.FamilyID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return mapDBError(err, "ROTATE_FAILED")
+	}
+	return domainErr
+}
+
+// checkRotation reports why current cannot be rotated at now, or nil if it
+// can. REFRESH_TOKEN_REUSED means the token was presented a second time.
+func checkRotation(current *auth.RefreshToken, now time.Time) *shared.DomainError {
+	switch {
+	case current.RevokedAt != nil:
+		return errRefreshTokenInvalid
+	case current.RotatedAt != nil:
+		return errRefreshTokenReused
+	case now.After(current.ExpiresAt):
+		return errRefreshTokenExpired
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	if err := revokeFamily(ctx, r.db.DB, familyID, time.Now().UTC()); err != nil {
+		return mapDBError(err, "REVOKE_FAILED")
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	if err := revokeUserSessions(ctx, r.db.DB, userID, time.Now().UTC()); err != nil {
+		return mapDBError(err, "REVOKE_FAILED")
+	}
+	return nil
+}
+
+// revokeFamily revokes the session started by a login and every refresh
+// token rotated from it.
+func revokeFamily(ctx context.Context, db bun.IDB, familyID uuid.UUID, at time.Time) error {
+	_, err := db.NewUpdate().
+		Model((*auth.Session)(nil)).
+		Set("revoked_at = ?", at).
+		Where("id = ?", familyID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewUpdate().
+		Model((*auth.RefreshToken)(nil)).
+		Set("revoked_at = ?", at).
+		Where("family_id = ?", familyID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	return err
+}
+
+// revokeUserSessions revokes every session of the user and their refresh
+// tokens. Access tokens of those sessions are rejected from then on.
+func revokeUserSessions(ctx context.Context, db bun.IDB, userID uuid.UUID, at time.Time) error {
+	_, err := db.NewUpdate().
+		Model((*auth.Session)(nil)).
+		Set("revoked_at = ?", at).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewUpdate().
+		Model((*auth.RefreshToken)(nil)).
+		Set("revoked_at = ?", at).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	return err
+}
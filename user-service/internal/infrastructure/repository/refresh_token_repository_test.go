@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+)
+
+func TestCheckRotation(t *testing.T) {
+	now := time.Now().UTC()
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token auth.RefreshToken
+		want  *shared.DomainError
+	}{
+		{"unused token", auth.RefreshToken{ExpiresAt: now.Add(time.Hour)}, nil},
+		{"replayed token", auth.RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &earlier}, errRefreshTokenReused},
+		{"replayed expired token", auth.RefreshToken{ExpiresAt: earlier, RotatedAt: &earlier}, errRefreshTokenReused},
+		{"expired token", auth.RefreshToken{ExpiresAt: earlier}, errRefreshTokenExpired},
+		{"revoked token", auth.RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &earlier}, errRefreshTokenInvalid},
+		// Once the family is revoked, replays no longer revoke it again.
+		{"revoked replayed token", auth.RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &earlier, RevokedAt: &earlier}, errRefreshTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkRotation(&tt.token, now); got != tt.want {
+				t.Errorf("checkRotation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var errUnknownRole = shared.NewDomainError("UNKNOWN_ROLE", 422, "one or more roles do not exist")
+
+type RoleRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ rbac.RoleRepository = (*RoleRepositoryImpl)(nil)
+
+func NewRoleRepository(db *database.Database) *RoleRepositoryImpl {
+	return &RoleRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *RoleRepositoryImpl) ListRoles(ctx context.Context) (*[]rbac.Role, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var roles []rbac.Role
+	err := r.db.DB.NewSelect().
+		Model(&roles).
+		ColumnExpr("r.*").
+		ColumnExpr("COALESCE(ARRAY_AGG(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}') AS permissions").
+		Join("LEFT JOIN role_permissions AS rp ON rp.role_id = r.id").
+		Join("LEFT JOIN permissions AS p ON p.id = rp.permission_id").
+		Group("r.id").
+		Order("r.name").
+		Scan(ctx)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return &roles, nil
+}
+
+func (r *RoleRepositoryImpl) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	permissions := []string{}
+	err := r.db.DB.NewSelect().
+		Model((*rbac.Permission)(nil)).
+		Distinct().
+		Column("p.name").
+		Join("JOIN role_permissions AS rp ON rp.permission_id = p.id").
+		Join("JOIN user_roles AS ur ON ur.role_id = rp.role_id").
+		Where("ur.user_id = ?", userID).
+		Order("p.name").
+		Scan(ctx, &permissions)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return permissions, nil
+}
+
+func (r *RoleRepositoryImpl) GetUserRoles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	roles := make(map[uuid.UUID][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return roles, nil
+	}
+
+	var rows []struct {
+		UserID uuid.UUID
+		Name   string
+	}
+	err := r.db.DB.NewSelect().
+		Model((*rbac.UserRole)(nil)).
+		Column("ur.user_id").
+		ColumnExpr("r.name").
+		Join("JOIN roles AS r ON r.id = ur.role_id").
+		Where("ur.user_id IN (?)", bun.In(userIDs)).
+		Order("r.name").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	for _, row := range rows {
+		roles[row.UserID] = append(roles[row.UserID], row.Name)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) SetUserRoles(ctx context.Context, userID uuid.UUID, names []string, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Lock the user so concurrent assignments are applied one at a time.
+		err := tx.NewSelect().
+			Model((*user.User)(nil)).
+			Column("u.id").
+			Where("u.id = ?", userID).
+			For("UPDATE").
+			Scan(ctx, new(uuid.UUID))
+		if err != nil {
+			return err
+		}
+
+		var roleIDs []uuid.UUID
+		if len(names) > 0 {
+			err = tx.NewSelect().
+				Model((*rbac.Role)(nil)).
+				Column("r.id").
+				Where("r.name IN (?)", bun.In(names)).
+				Scan(ctx, &roleIDs)
+			if err != nil {
+				return err
+			}
+			if len(roleIDs) != len(names) {
+				return errUnknownRole
+			}
+		}
+
+		oldRoles := []string{}
+		err = tx.NewSelect().
+			Model((*rbac.UserRole)(nil)).
+			ColumnExpr("r.name").
+			Join("JOIN roles AS r ON r.id = ur.role_id").
+			Where("ur.user_id = ?", userID).
+			Order("r.name").
+			Scan(ctx, &oldRoles)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*rbac.UserRole)(nil)).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(roleIDs) > 0 {
+			assignments := make([]rbac.UserRole, len(roleIDs))
+			for i, roleID := range roleIDs {
+				assignments[i] = rbac.UserRole{UserID: userID, RoleID: roleID}
+			}
+			if _, err := tx.NewInsert().Model(&assignments).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return writeAudit(ctx, tx, actor.Entry(userID, audit.ActionRolesChanged, map[string]any{
+			"old_roles": oldRoles,
+			"new_roles": names,
+		}))
+	})
+	if err != nil {
+		if errors.Is(err, errUnknownRole) {
+			return errUnknownRole
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
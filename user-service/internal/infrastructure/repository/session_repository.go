@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var errSessionNotFound = shared.NewDomainError("SESSION_NOT_FOUND", 404, "session not found")
+
+type SessionRepositoryImpl struct {
+	db *database.Database
+}
+
+// Compile-time interface check
+var _ auth.SessionRepository = (*SessionRepositoryImpl)(nil)
+
+func NewSessionRepository(db *database.Database) *SessionRepositoryImpl {
+	return &SessionRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *SessionRepositoryImpl) CreateSession(ctx context.Context, session *auth.Session, token *auth.RefreshToken) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(session).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		token.UserID = session.UserID
+		token.FamilyID = session.ID
+		_, err := tx.NewInsert().Model(token).Returning("id").Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return mapDBError(err, "CREATE_FAILED")
+	}
+	return nil
+}
+
+func (r *SessionRepositoryImpl) GetSession(ctx context.Context, id uuid.UUID) (*auth.Session, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	session := new(auth.Session)
+	err := r.db.DB.NewSelect().Model(session).Where("s.id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errSessionNotFound
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return session, nil
+}
+
+func (r *SessionRepositoryImpl) TouchSession(ctx context.Context, id uuid.UUID, ip string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.DB.NewUpdate().
+		Model((*auth.Session)(nil)).
+		Set("last_seen_at = ?", time.Now().UTC()).
+		Set("ip = COALESCE(NULLIF(?, ''), ip)", ip).
+		Where("id = ?", id).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *SessionRepositoryImpl) ListActiveSessions(ctx context.Context, userID uuid.UUID) (*[]auth.Session, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var sessions []auth.Session
+	err := r.db.DB.NewSelect().
+		Model(&sessions).
+		Where("s.user_id = ?", userID).
+		Where("s.revoked_at IS NULL").
+		Where("s.expires_at > ?", time.Now().UTC()).
+		Order("s.last_seen_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return &sessions, nil
+}
+
+func (r *SessionRepositoryImpl) RevokeSession(ctx context.Context, userID, id uuid.UUID) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Only sessions owned by the user may be revoked.
+		exists, err := tx.NewSelect().
+			Model((*auth.Session)(nil)).
+			Where("s.id = ?", id).
+			Where("s.user_id = ?", userID).
+			Where("s.revoked_at IS NULL").
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+		return revokeFamily(ctx, tx, id, time.Now().UTC())
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errSessionNotFound
+		}
+		return mapDBError(err, "REVOKE_FAILED")
+	}
+	return nil
+}
+
+func (r *SessionRepositoryImpl) RevokeOtherSessions(ctx context.Context, userID, keepID uuid.UUID) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now().UTC()
+		_, err := tx.NewUpdate().
+			Model((*auth.Session)(nil)).
+			Set("revoked_at = ?", now).
+			Where("user_id = ?", userID).
+			Where("id != ?", keepID).
+			Where("revoked_at IS NULL").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*auth.RefreshToken)(nil)).
+			Set("revoked_at = ?", now).
+			Where("user_id = ?", userID).
+			Where("family_id != ?", keepID).
+			Where("revoked_at IS NULL").
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return mapDBError(err, "REVOKE_FAILED")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var errInvalidResetToken = shared.NewDomainError("INVALID_RESET_TOKEN", 400, "reset token is invalid or expired")
+
+func (r *UserRepositoryImpl) ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now().UTC()
+		res, err := tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("password_hash = ?", passwordHash).
+			Set("updated_at = ?", now).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		if err := writeAudit(ctx, tx, actor.Entry(id, audit.ActionPasswordChanged, nil)); err != nil {
+			return err
+		}
+		return revokeUserSessions(ctx, tx, id, now)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) CreatePasswordResetToken(ctx context.Context, token *user.PasswordResetToken) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model((*user.PasswordResetToken)(nil)).
+			Where("user_id = ?", token.UserID).
+			Where("used_at IS NULL").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewInsert().Model(token).Returning("id").Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return mapDBError(err, "CREATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) CheckPasswordResetToken(ctx context.Context, tokenHash string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	// Reset links are opened right after they are sent, so read from the
+	// primary rather than a replica that may not have the token yet.
+	exists, err := r.db.DB.NewSelect().
+		Model((*user.PasswordResetToken)(nil)).
+		Where("token_hash = ?", tokenHash).
+		Where("used_at IS NULL").
+		Where("expires_at > ?", time.Now().UTC()).
+		Exists(ctx)
+	if err != nil {
+		return mapDBError(err, "FETCH_FAILED")
+	}
+	if !exists {
+		return errInvalidResetToken
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) ResetPassword(ctx context.Context, tokenHash, passwordHash string, actor audit.Actor) (uuid.UUID, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	var (
+		userID    uuid.UUID
+		domainErr *shared.DomainError
+	)
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now().UTC()
+		// Consuming the token in the UPDATE makes concurrent resets with the
+		// same token race-free: only one of them gets the row back.
+		token := new(user.PasswordResetToken)
+		err := tx.NewUpdate().
+			Model(token).
+			Set("used_at = ?", now).
+			Where("token_hash = ?", tokenHash).
+			Where("used_at IS NULL").
+			Where("expires_at > ?", now).
+			Returning("*").
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				domainErr = errInvalidResetToken
+				return nil
+			}
+			return err
+		}
+
+		// The reset link was delivered to the address, which proves ownership
+		_, err = tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("password_hash = ?", passwordHash).
+			Set("email_verified_at = COALESCE(email_verified_at, ?)", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", token.UserID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+		if err := writeAudit(ctx, tx, actor.Entry(token.UserID, audit.ActionPasswordReset, nil)); err != nil {
+			return err
+		}
+		return revokeUserSessions(ctx, tx, token.UserID, now)
+	})
+	if err != nil {
+		return uuid.Nil, mapDBError(err, "UPDATE_FAILED")
+	}
+	if domainErr != nil {
+		return uuid.Nil, domainErr
+	}
+	return userID, nil
+}
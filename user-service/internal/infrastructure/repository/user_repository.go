@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/Nezent/microservice-template/user-service/internal/domain/audit"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
 	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/database"
@@ -24,8 +28,8 @@ func NewUserRepository(db *database.Database) *UserRepositoryImpl {
 	}
 }
 
-func (r *UserRepositoryImpl) CreateUser(user *user.User) (uuid.UUID, *shared.DomainError) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *user.User, actor audit.Actor) (uuid.UUID, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
 	defer cancel()
 
 	// Start transaction
@@ -35,22 +39,274 @@ func (r *UserRepositoryImpl) CreateUser(user *user.User) (uuid.UUID, *shared.Dom
 		if err != nil {
 			return err
 		}
-		return nil
+		return writeAudit(ctx, tx, actor.Entry(user.ID, audit.ActionUserCreated, map[string]any{
+			"email": user.Email,
+		}))
 	})
 	if err != nil {
-		return uuid.Nil, shared.NewDomainError("CREATE_FAILED", 500, err.Error())
+		return uuid.Nil, mapDBError(err, "CREATE_FAILED")
 	}
 	return user.ID, nil
 }
 
-func (r *UserRepositoryImpl) GetUser() (*[]user.User, *shared.DomainError) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// GetUser returns one page of users using keyset pagination. It fetches
+// filter.Limit+1 rows so the caller can tell whether another page exists.
+func (r *UserRepositoryImpl) GetUser(ctx context.Context, filter *user.UserFilter) (*[]user.User, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
 	defer cancel()
 
+	column, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		return nil, shared.NewDomainError("INVALID_SORT", 400, "unsupported sort field")
+	}
+	direction, cmp := "ASC", ">"
+	if filter.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
 	var users []user.User
-	err := r.db.DB.NewSelect().Model(&users).Scan(ctx)
+	q := r.db.Reader(ctx).NewSelect().Model(&users)
+
+	if filter.EmailPrefix != "" {
+		q = q.Where(`u.email LIKE ? ESCAPE '\'`, escapeLike(filter.EmailPrefix)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		q = q.Where("u.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q = q.Where("u.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.After != nil {
+		value, err := cursorValue(filter.SortBy, filter.After.SortValue)
+		if err != nil {
+			return nil, shared.NewDomainError("INVALID_CURSOR", 400, "cursor is invalid")
+		}
+		q = q.Where("(?, u.id) "+cmp+" (?, ?)", bun.Ident(column), value, filter.After.ID)
+	}
+
+	err := q.
+		OrderExpr("? "+direction+", u.id "+direction, bun.Ident(column)).
+		Limit(filter.Limit + 1).
+		Scan(ctx)
 	if err != nil {
-		return nil, shared.NewDomainError("FETCH_FAILED", 500, err.Error())
+		return nil, mapDBError(err, "FETCH_FAILED")
 	}
 	return &users, nil
 }
+
+func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id uuid.UUID) (*user.User, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	u := &user.User{ID: id}
+	err := r.db.Reader(ctx).NewSelect().Model(u).WherePK().Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return u, nil
+}
+
+func (r *UserRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (*user.User, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	u := new(user.User)
+	err := r.db.Reader(ctx).NewSelect().Model(u).Where("email = ?", email).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return nil, mapDBError(err, "FETCH_FAILED")
+	}
+	return u, nil
+}
+
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	u := &user.User{ID: id, Password: passwordHash}
+	_, err := r.db.DB.NewUpdate().
+		Model(u).
+		Column("password_hash", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdateUser(ctx context.Context, u *user.User) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.DB.NewUpdate().
+		Model(u).
+		Column("name", "updated_at").
+		WherePK().
+		Returning("*").
+		Exec(ctx, u)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdateEmail(ctx context.Context, id uuid.UUID, email string, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var oldEmail string
+		err := tx.NewSelect().
+			Model((*user.User)(nil)).
+			Column("u.email").
+			Where("u.id = ?", id).
+			For("UPDATE").
+			Scan(ctx, &oldEmail)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("email = ?", email).
+			Set("email_verified_at = NULL").
+			Set("updated_at = ?", time.Now().UTC()).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return writeAudit(ctx, tx, actor.Entry(id, audit.ActionEmailChanged, map[string]any{
+			"old_email": oldEmail,
+			"new_email": email,
+		}))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	res, err := r.db.DB.NewUpdate().
+		Model((*user.User)(nil)).
+		Set("email_verified_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("id = ?", id).
+		Where("email = ?", email).
+		Exec(ctx)
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return shared.NewDomainError("INVALID_VERIFICATION_TOKEN", 400, "verification token is invalid or expired")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) DeleteUser(ctx context.Context, id uuid.UUID, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var email string
+		err := tx.NewDelete().
+			Model((*user.User)(nil)).
+			Where("id = ?", id).
+			Returning("email").
+			Scan(ctx, &email)
+		if err != nil {
+			return err
+		}
+		// Keep the address so the entry stays meaningful once the user is gone
+		return writeAudit(ctx, tx, actor.Entry(id, audit.ActionUserDeleted, map[string]any{
+			"email": email,
+		}))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "DELETE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool, actor audit.Actor) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now().UTC()
+		q := tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("updated_at = ?", now).
+			Where("id = ?", id)
+		if disabled {
+			// Keep the original timestamp when the user is already disabled.
+			q = q.Set("disabled_at = COALESCE(disabled_at, ?)", now)
+		} else {
+			q = q.Set("disabled_at = NULL")
+		}
+		res, err := q.Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		if !disabled {
+			return writeAudit(ctx, tx, actor.Entry(id, audit.ActionUserEnabled, nil))
+		}
+		if err := writeAudit(ctx, tx, actor.Entry(id, audit.ActionUserDisabled, nil)); err != nil {
+			return err
+		}
+		return revokeUserSessions(ctx, tx, id, now)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shared.NewDomainError("USER_NOT_FOUND", 404, "user not found")
+		}
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+// userSortColumns whitelists the columns users may be ordered by.
+var userSortColumns = map[string]string{
+	user.SortByCreatedAt: "u.created_at",
+	user.SortByEmail:     "u.email",
+	user.SortByName:      "u.name",
+}
+
+// cursorValue converts the cursor's sort value to the column's type.
+func cursorValue(sortBy, raw string) (any, error) {
+	if sortBy == user.SortByCreatedAt {
+		return time.Parse(time.RFC3339Nano, raw)
+	}
+	return raw, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
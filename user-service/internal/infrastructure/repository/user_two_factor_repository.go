@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// SetTOTPSecret stores a pending TOTP secret. It is rejected once two-factor
+// authentication is enabled so an active secret cannot be silently replaced.
+func (r *UserRepositoryImpl) SetTOTPSecret(ctx context.Context, id uuid.UUID, sealedSecret string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.DB.NewUpdate().
+		Model((*user.User)(nil)).
+		Set("totp_secret = ?", sealedSecret).
+		Set("updated_at = ?", time.Now().UTC()).
+		Where("id = ?", id).
+		Where("totp_enabled_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return shared.NewDomainError("TWO_FACTOR_ALREADY_ENABLED", 409, "two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) EnableTOTP(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now().UTC()
+		_, err := tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("totp_enabled_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, id, recoveryCodeHashes)
+	})
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) DisableTOTP(ctx context.Context, id uuid.UUID) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*user.User)(nil)).
+			Set("totp_secret = NULL").
+			Set("totp_enabled_at = NULL").
+			Set("totp_last_step = NULL").
+			Set("updated_at = ?", time.Now().UTC()).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, id, nil)
+	})
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) *shared.DomainError {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	err := r.db.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, id, recoveryCodeHashes)
+	})
+	if err != nil {
+		return mapDBError(err, "UPDATE_FAILED")
+	}
+	return nil
+}
+
+// ConsumeTOTPStep records step as the last accepted TOTP step. It returns false
+// if the same or a later step was already used, which rejects replayed codes.
+func (r *UserRepositoryImpl) ConsumeTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.DB.NewUpdate().
+		Model((*user.User)(nil)).
+		Set("totp_last_step = ?", step).
+		Where("id = ?", id).
+		Where("totp_last_step IS NULL OR totp_last_step < ?", step).
+		Exec(ctx)
+	if err != nil {
+		return false, mapDBError(err, "UPDATE_FAILED")
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+func (r *UserRepositoryImpl) ConsumeRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, *shared.DomainError) {
+	ctx, cancel := r.db.WithTimeout(ctx)
+	defer cancel()
+
+	res, err := r.db.DB.NewUpdate().
+		Model((*user.RecoveryCode)(nil)).
+		Set("used_at = ?", time.Now().UTC()).
+		Where("user_id = ?", id).
+		Where("code_hash = ?", codeHash).
+		Where("used_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return false, mapDBError(err, "UPDATE_FAILED")
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx bun.Tx, id uuid.UUID, hashes []string) error {
+	_, err := tx.NewDelete().
+		Model((*user.RecoveryCode)(nil)).
+		Where("user_id = ?", id).
+		Exec(ctx)
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+
+	codes := make([]user.RecoveryCode, len(hashes))
+	for i, h := range hashes {
+		codes[i] = user.RecoveryCode{UserID: id, CodeHash: h}
+	}
+	_, err = tx.NewInsert().Model(&codes).Exec(ctx)
+	return err
+}
@@ -0,0 +1,101 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired entries are dropped from memory.
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	failures    int
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+// MemoryStore keeps counters in process memory. It is only suitable for a
+// single instance; use RedisStore when the service is scaled out.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// Compile-time interface check
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+	return max(time.Until(e.lockedUntil), 0), nil
+}
+
+func (s *MemoryStore) Fail(_ context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expiresAt) {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.failures++
+	if until := now.Add(window); until.After(e.expiresAt) {
+		e.expiresAt = until
+	}
+	return e.failures, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, d, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.lockedUntil = time.Now().Add(d)
+	if until := e.lockedUntil.Add(window); until.After(e.expiresAt) {
+		e.expiresAt = until
+	}
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries. The caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package throttle
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"throttle",
+	fx.Provide(
+		ProvideStore,
+		fx.Annotate(
+			ProvideThrottler,
+			fx.As(new(auth.LoginThrottler)),
+		),
+	),
+)
@@ -0,0 +1,64 @@
+package throttle
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	redisFailPrefix = "login_throttle:fail:"
+	redisLockPrefix = "login_throttle:lock:"
+)
+
+// RedisStore shares counters between instances and relies on key expiry for
+// cleanup.
+type RedisStore struct {
+	client *goredis.Client
+}
+
+// Compile-time interface check
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client *goredis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+func (s *RedisStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	d, err := s.client.PTTL(ctx, redisLockPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Missing keys report a negative TTL.
+	return max(d, 0), nil
+}
+
+// failScript increments the counter and extends its expiry to window without
+// shortening a longer expiry set by Lock.
+var failScript = goredis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+func (s *RedisStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	return failScript.Run(ctx, s.client, []string{redisFailPrefix + key}, window.Milliseconds()).Int()
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key string, d, window time.Duration) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, redisLockPrefix+key, 1, d)
+		pipe.PExpire(ctx, redisFailPrefix+key, d+window)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, redisFailPrefix+key, redisLockPrefix+key).Err()
+}
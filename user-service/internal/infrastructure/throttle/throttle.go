@@ -0,0 +1,167 @@
+package throttle
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Store keeps failure counters and lockouts per key.
+type Store interface {
+	// LockedFor returns the remaining lockout of key, or zero.
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Fail counts a failed attempt for key and returns the failures seen so
+	// far. The counter is forgotten once window passes without a failure.
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock locks key out for d and keeps its counter for at least d plus
+	// window, so the next lockout backs off further.
+	Lock(ctx context.Context, key string, d, window time.Duration) error
+	// Reset forgets the counter and lockout of key.
+	Reset(ctx context.Context, key string) error
+}
+
+// Throttler implements auth.LoginThrottler on top of a Store. Stores that
+// fail are logged and ignored, so an unavailable Redis never blocks sign-in.
+type Throttler struct {
+	store  Store
+	cfg    config.ThrottleConfig
+	logger logger.Logger
+}
+
+// Compile-time interface check
+var _ auth.LoginThrottler = (*Throttler)(nil)
+
+func NewThrottler(store Store, cfg config.ThrottleConfig, log logger.Logger) *Throttler {
+	return &Throttler{
+		store:  store,
+		cfg:    cfg,
+		logger: log.Named("security"),
+	}
+}
+
+// scopedKey is a throttled key and the free attempts it is allowed.
+type scopedKey struct {
+	scope    string
+	value    string
+	attempts int
+}
+
+func (k scopedKey) String() string {
+	return k.scope + ":" + k.value
+}
+
+func (t *Throttler) Check(ip, account string) time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var wait time.Duration
+	for _, key := range t.keys(ip, account) {
+		d, err := t.store.LockedFor(ctx, key.String())
+		if err != nil {
+			t.logger.Error("login throttle check failed", zap.String("key", key.String()), zap.Error(err))
+			continue
+		}
+		wait = max(wait, d)
+	}
+	return wait
+}
+
+func (t *Throttler) Failure(ip, account string) time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.logger.Info("login failed",
+		zap.String("event", "login_failed"),
+		zap.String("ip", ip),
+		zap.String("account", normalizeAccount(account)),
+	)
+
+	var wait time.Duration
+	for _, key := range t.keys(ip, account) {
+		failures, err := t.store.Fail(ctx, key.String(), t.cfg.Window)
+		if err != nil {
+			t.logger.Error("login throttle update failed", zap.String("key", key.String()), zap.Error(err))
+			continue
+		}
+		if failures <= key.attempts {
+			continue
+		}
+
+		lockout := t.backoff(failures - key.attempts)
+		if err := t.store.Lock(ctx, key.String(), lockout, t.cfg.Window); err != nil {
+			t.logger.Error("login throttle update failed", zap.String("key", key.String()), zap.Error(err))
+			continue
+		}
+		t.logger.Warn("login locked out",
+			zap.String("event", "login_lockout"),
+			zap.String("scope", key.scope),
+			zap.String(key.scope, key.value),
+			zap.Int("failures", failures),
+			zap.Duration("lockout", lockout),
+		)
+		wait = max(wait, lockout)
+	}
+	return wait
+}
+
+// Success only resets the account. The IP counter keeps running, otherwise
+// an attacker could clear it by signing in to an account of their own.
+func (t *Throttler) Success(_, account string) {
+	account = normalizeAccount(account)
+	if account == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	key := scopedKey{scope: "account", value: account}
+	if err := t.store.Reset(ctx, key.String()); err != nil {
+		t.logger.Error("login throttle reset failed", zap.String("key", key.String()), zap.Error(err))
+	}
+}
+
+func (t *Throttler) keys(ip, account string) []scopedKey {
+	keys := make([]scopedKey, 0, 2)
+	if ip != "" {
+		keys = append(keys, scopedKey{scope: "ip", value: ip, attempts: t.cfg.IPAttempts})
+	}
+	if account = normalizeAccount(account); account != "" {
+		keys = append(keys, scopedKey{scope: "account", value: account, attempts: t.cfg.AccountAttempts})
+	}
+	return keys
+}
+
+// backoff returns the lockout for the nth failure beyond the free attempts:
+// base_lockout doubled n-1 times, capped at max_lockout.
+func (t *Throttler) backoff(n int) time.Duration {
+	lockout := t.cfg.BaseLockout
+	for i := 1; i < n && lockout < t.cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, t.cfg.MaxLockout)
+}
+
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+// ProvideStore selects the store configured by throttle.driver.
+func ProvideStore(cfg *config.Config, client *goredis.Client) (Store, error) {
+	if err := cfg.Throttle.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Throttle.Driver == "redis" {
+		return NewRedisStore(client), nil
+	}
+	return NewMemoryStore(), nil
+}
+
+func ProvideThrottler(cfg *config.Config, store Store, log logger.Logger) *Throttler {
+	return NewThrottler(store, cfg.Throttle, log)
+}
@@ -0,0 +1,169 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+var testConfig = config.ThrottleConfig{
+	Window:          time.Hour,
+	IPAttempts:      4,
+	AccountAttempts: 2,
+	BaseLockout:     time.Minute,
+	MaxLockout:      5 * time.Minute,
+}
+
+// nopLogger discards everything.
+type nopLogger struct{ logger.Logger }
+
+func (l nopLogger) Named(string) logger.Logger { return l }
+func (nopLogger) Info(string, ...zap.Field)    {}
+func (nopLogger) Warn(string, ...zap.Field)    {}
+func (nopLogger) Error(string, ...zap.Field)   {}
+
+func newTestThrottler(store Store) *Throttler {
+	return NewThrottler(store, testConfig, nopLogger{})
+}
+
+func TestThrottlerLocksOutAccount(t *testing.T) {
+	th := newTestThrottler(NewMemoryStore())
+
+	for i := range testConfig.AccountAttempts {
+		if wait := th.Failure("192.0.2.1", "jane@example.com"); wait != 0 {
+			t.Fatalf("failure %d locked out for %s, want free attempts", i+1, wait)
+		}
+	}
+	if wait := th.Failure("192.0.2.1", "jane@example.com"); wait != time.Minute {
+		t.Fatalf("failure beyond the free attempts locked out for %s, want 1m", wait)
+	}
+
+	tests := []struct {
+		name    string
+		ip      string
+		account string
+		locked  bool
+	}{
+		{"same account and ip", "192.0.2.1", "jane@example.com", true},
+		{"same account from another ip", "192.0.2.2", "jane@example.com", true},
+		{"same account in another case", "192.0.2.2", " Jane@Example.com", true},
+		{"other account from the same ip", "192.0.2.1", "john@example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if wait := th.Check(tt.ip, tt.account); (wait > 0) != tt.locked {
+				t.Errorf("Check = %s, want locked %v", wait, tt.locked)
+			}
+		})
+	}
+}
+
+func TestThrottlerLocksOutIP(t *testing.T) {
+	th := newTestThrottler(NewMemoryStore())
+
+	// Spread over accounts so that only the IP runs out of attempts
+	accounts := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	var wait time.Duration
+	for _, account := range accounts {
+		wait = th.Failure("192.0.2.1", account)
+	}
+	if wait == 0 {
+		t.Fatal("IP not locked out after running out of attempts")
+	}
+	if th.Check("192.0.2.1", "new@example.com") == 0 {
+		t.Error("locked out IP can try another account")
+	}
+	if th.Check("192.0.2.2", "new@example.com") != 0 {
+		t.Error("other IP is locked out")
+	}
+}
+
+func TestThrottlerBacksOff(t *testing.T) {
+	th := newTestThrottler(NewMemoryStore())
+
+	var got []time.Duration
+	for range testConfig.AccountAttempts + 5 {
+		if wait := th.Failure("", "jane@example.com"); wait > 0 {
+			got = append(got, wait)
+		}
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	if len(got) != len(want) {
+		t.Fatalf("lockouts = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("lockouts = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+// Signing in resets the account but not the IP, so an attacker cannot clear
+// their IP's failures by signing in to an account of their own.
+func TestThrottlerSuccessResetsAccountOnly(t *testing.T) {
+	th := newTestThrottler(NewMemoryStore())
+
+	for range testConfig.IPAttempts {
+		th.Failure("192.0.2.1", "jane@example.com")
+	}
+	th.Success("192.0.2.1", "jane@example.com")
+
+	if wait := th.Check("", "jane@example.com"); wait != 0 {
+		t.Errorf("account locked out for %s after signing in", wait)
+	}
+	if wait := th.Failure("192.0.2.1", "other@example.com"); wait == 0 {
+		t.Error("IP failures were reset by signing in")
+	}
+}
+
+// failingStore fails every operation.
+type failingStore struct{}
+
+func (failingStore) LockedFor(context.Context, string) (time.Duration, error) {
+	return 0, errors.New("unavailable")
+}
+
+func (failingStore) Fail(context.Context, string, time.Duration) (int, error) {
+	return 0, errors.New("unavailable")
+}
+
+func (failingStore) Lock(context.Context, string, time.Duration, time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func (failingStore) Reset(context.Context, string) error {
+	return errors.New("unavailable")
+}
+
+// An unavailable store must not lock everyone out.
+func TestThrottlerFailsOpen(t *testing.T) {
+	th := newTestThrottler(failingStore{})
+
+	for range testConfig.IPAttempts + 1 {
+		if wait := th.Failure("192.0.2.1", "jane@example.com"); wait != 0 {
+			t.Fatalf("Failure = %s, want 0", wait)
+		}
+	}
+	if wait := th.Check("192.0.2.1", "jane@example.com"); wait != 0 {
+		t.Errorf("Check = %s, want 0", wait)
+	}
+}
+
+func TestMemoryStoreForgetsAfterWindow(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	if n, _ := s.Fail(ctx, "k", time.Millisecond); n != 1 {
+		t.Fatalf("first Fail = %d, want 1", n)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if n, _ := s.Fail(ctx, "k", time.Hour); n != 1 {
+		t.Errorf("Fail after the window = %d, want a fresh count of 1", n)
+	}
+}
@@ -0,0 +1,230 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Every token use is also the token's audience ("aud"). All uses are signed
+// with the same key and issuer, so services that accept access tokens through
+// the JWKS must require aud to be "access" besides checking iss and exp;
+// otherwise an MFA challenge or an email verification link would pass as an
+// access token.
+const (
+	tokenUseAccess            = "access"
+	tokenUseMFA               = "mfa"
+	tokenUseEmailVerification = "email_verification"
+	tokenUseAdmin             = "admin"
+)
+
+// accessClaims is the JWT payload of every token issued by the manager;
+// TokenUse tells them apart.
+type accessClaims struct {
+	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	SessionID     string   `json:"sid,omitempty"`
+	TokenUse      string   `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// JWTManager signs and verifies access tokens with an asymmetric key pair
+// so that other services only need the public JWKS to verify them.
+type JWTManager struct {
+	method     jwt.SigningMethod
+	keys       *keyPair
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	mfaTTL     time.Duration
+	verifyTTL  time.Duration
+}
+
+// Compile-time interface checks
+var (
+	_ auth.TokenManager       = (*JWTManager)(nil)
+	_ auth.AdminTokenManager  = (*JWTManager)(nil)
+	_ user.VerificationTokens = (*JWTManager)(nil)
+)
+
+// NewJWTManager creates a JWTManager from the JWT configuration. mfaTTL is the
+// lifetime of second-step login challenges and verifyTTL that of email
+// verification links. When generate is set, a missing key pair is created on
+// disk.
+func NewJWTManager(cfg config.JWTConfig, mfaTTL, verifyTTL time.Duration, issuer string, generate bool) (*JWTManager, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid jwt config: %w", err)
+	}
+
+	keys, err := loadKeyPair(cfg.Algorithm, cfg.PrivateKey, cfg.PublicKey, generate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	return &JWTManager{
+		method:     jwt.GetSigningMethod(cfg.Algorithm),
+		keys:       keys,
+		issuer:     issuer,
+		accessTTL:  cfg.AccessTokenExpiresIn,
+		refreshTTL: cfg.RefreshTokenExpiresIn,
+		mfaTTL:     mfaTTL,
+		verifyTTL:  verifyTTL,
+	}, nil
+}
+
+func (m *JWTManager) IssueAccessToken(u *user.User, sessionID uuid.UUID) (*auth.AccessToken, error) {
+	return m.issue(u.ID.String(), accessClaims{
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified(),
+		SessionID:     sessionID.String(),
+		TokenUse:      tokenUseAccess,
+	}, m.accessTTL)
+}
+
+func (m *JWTManager) VerifyAccessToken(token string) (*auth.Claims, error) {
+	return m.verify(token, tokenUseAccess)
+}
+
+func (m *JWTManager) IssueMFAToken(u *user.User) (*auth.AccessToken, error) {
+	return m.issue(u.ID.String(), accessClaims{TokenUse: tokenUseMFA}, m.mfaTTL)
+}
+
+func (m *JWTManager) VerifyMFAToken(token string) (*auth.Claims, error) {
+	return m.verify(token, tokenUseMFA)
+}
+
+func (m *JWTManager) IssueEmailVerificationToken(u *user.User) (string, error) {
+	t, err := m.issue(u.ID.String(), accessClaims{
+		Email:    u.Email,
+		TokenUse: tokenUseEmailVerification,
+	}, m.verifyTTL)
+	if err != nil {
+		return "", err
+	}
+	return t.Token, nil
+}
+
+func (m *JWTManager) VerifyEmailVerificationToken(token string) (uuid.UUID, string, error) {
+	claims, err := m.verify(token, tokenUseEmailVerification)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	id, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return id, claims.Email, nil
+}
+
+func (m *JWTManager) IssueAdminToken(u *user.User, permissions []string) (*auth.AccessToken, error) {
+	return m.issue(u.ID.String(), accessClaims{
+		Email:       u.Email,
+		Permissions: permissions,
+		TokenUse:    tokenUseAdmin,
+	}, m.accessTTL)
+}
+
+func (m *JWTManager) VerifyAdminToken(token string) (*auth.Claims, error) {
+	return m.verify(token, tokenUseAdmin)
+}
+
+func (m *JWTManager) IssueRefreshToken() (*auth.OpaqueToken, error) {
+	return NewOpaqueToken(m.refreshTTL)
+}
+
+func (m *JWTManager) HashToken(token string) string {
+	return HashOpaqueToken(token)
+}
+
+func (m *JWTManager) JWKS() *dto.JWKSResponse {
+	return &dto.JWKSResponse{Keys: []dto.JWK{m.keys.jwk(m.method.Alg())}}
+}
+
+// issue signs claims for subject; the registered claims are filled in here.
+func (m *JWTManager) issue(subject string, claims accessClaims, ttl time.Duration) (*auth.AccessToken, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    m.issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{claims.TokenUse},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	t := jwt.NewWithClaims(m.method, claims)
+	t.Header["kid"] = m.keys.kid
+
+	signed, err := t.SignedString(m.keys.private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s token: %w", claims.TokenUse, err)
+	}
+	return &auth.AccessToken{Token: signed, ExpiresAt: expiresAt}, nil
+}
+
+// verify parses the token and ensures it was issued for the expected use and
+// audience, so that an MFA challenge can never be used as an access token.
+func (m *JWTManager) verify(token, use string) (*auth.Claims, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, m.keyFunc,
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(use),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != use {
+		return nil, fmt.Errorf("unexpected token use: %q", claims.TokenUse)
+	}
+
+	return &auth.Claims{
+		TokenID:       claims.ID,
+		SessionID:     claims.SessionID,
+		UserID:        claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Permissions:   claims.Permissions,
+		IssuedAt:      claims.IssuedAt.Time,
+		ExpiresAt:     claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (m *JWTManager) keyFunc(t *jwt.Token) (any, error) {
+	if kid, ok := t.Header["kid"].(string); ok && kid != m.keys.kid {
+		return nil, errors.New("unknown key id")
+	}
+	return m.keys.public, nil
+}
+
+// ProvideJWTManager provides the user token manager for dependency injection.
+// Missing keys are generated outside of production to ease local setup.
+func ProvideJWTManager(cfg *config.Config) (*JWTManager, error) {
+	if err := cfg.Auth.EmailVerification.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid email verification config: %w", err)
+	}
+	return NewJWTManager(cfg.Auth.JWT, cfg.Auth.OTP.ExpiresIn, cfg.Auth.EmailVerification.ExpiresIn, cfg.App.URL, cfg.App.Env != "production")
+}
+
+// ProvideAdminJWTManager provides the admin token manager. It is configured
+// from AdminAuth and uses its own issuer, so admin and user tokens are never
+// interchangeable even if both are configured with the same key pair.
+func ProvideAdminJWTManager(cfg *config.Config) (*JWTManager, error) {
+	if cfg.AdminAuth.OTP.ExpiresIn <= 0 {
+		return nil, fmt.Errorf("invalid admin auth config: otp expires_in must be positive")
+	}
+	return NewJWTManager(cfg.AdminAuth.JWT, cfg.AdminAuth.OTP.ExpiresIn, 0, cfg.App.URL+"/admin", cfg.App.Env != "production")
+}
@@ -0,0 +1,196 @@
+package token
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func newTestJWTManager(t *testing.T, algorithm, dir, issuer string, accessTTL time.Duration) *JWTManager {
+	t.Helper()
+	m, err := NewJWTManager(config.JWTConfig{
+		Algorithm:             algorithm,
+		PrivateKey:            filepath.Join(dir, "private.key"),
+		PublicKey:             filepath.Join(dir, "public.key"),
+		AccessTokenExpiresIn:  accessTTL,
+		RefreshTokenExpiresIn: time.Hour,
+	}, 5*time.Minute, time.Hour, issuer, true)
+	if err != nil {
+		t.Fatalf("NewJWTManager(%s): %v", algorithm, err)
+	}
+	return m
+}
+
+func testUser() *user.User {
+	now := time.Now()
+	return &user.User{ID: uuid.New(), Email: "jane@example.com", EmailVerifiedAt: &now}
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "EdDSA"} {
+		t.Run(algorithm, func(t *testing.T) {
+			m := newTestJWTManager(t, algorithm, t.TempDir(), "https://issuer.test", time.Minute)
+			u := testUser()
+			sessionID := uuid.New()
+
+			issued, err := m.IssueAccessToken(u, sessionID)
+			if err != nil {
+				t.Fatalf("IssueAccessToken: %v", err)
+			}
+			claims, err := m.VerifyAccessToken(issued.Token)
+			if err != nil {
+				t.Fatalf("VerifyAccessToken: %v", err)
+			}
+			if claims.UserID != u.ID.String() || claims.Email != u.Email || !claims.EmailVerified || claims.SessionID != sessionID.String() {
+				t.Errorf("claims = %+v, want user %s with session %s", claims, u.ID, sessionID)
+			}
+			var raw jwt.RegisteredClaims
+			if _, _, err := jwt.NewParser().ParseUnverified(issued.Token, &raw); err != nil || len(raw.Audience) != 1 || raw.Audience[0] != "access" {
+				t.Errorf("aud = %v (%v), want [access]", raw.Audience, err)
+			}
+			if jwks := m.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Alg != algorithm {
+				t.Errorf("JWKS = %+v, want one %s key", jwks, algorithm)
+			}
+		})
+	}
+}
+
+// Tokens are only accepted for the use they were issued for.
+func TestTokenUseIsEnforced(t *testing.T) {
+	m := newTestJWTManager(t, "EdDSA", t.TempDir(), "https://issuer.test", time.Minute)
+	u := testUser()
+
+	access, _ := m.IssueAccessToken(u, uuid.New())
+	mfa, _ := m.IssueMFAToken(u)
+	admin, _ := m.IssueAdminToken(u, []string{"users:read"})
+	verification, _ := m.IssueEmailVerificationToken(u)
+
+	verifiers := map[string]func(string) error{
+		"access": func(s string) error { _, err := m.VerifyAccessToken(s); return err },
+		"mfa":    func(s string) error { _, err := m.VerifyMFAToken(s); return err },
+		"admin":  func(s string) error { _, err := m.VerifyAdminToken(s); return err },
+		"email_verification": func(s string) error {
+			_, _, err := m.VerifyEmailVerificationToken(s)
+			return err
+		},
+	}
+	tokens := map[string]string{
+		"access":             access.Token,
+		"mfa":                mfa.Token,
+		"admin":              admin.Token,
+		"email_verification": verification,
+	}
+
+	for issuedAs, token := range tokens {
+		for verifiedAs, verify := range verifiers {
+			t.Run(issuedAs+" as "+verifiedAs, func(t *testing.T) {
+				err := verify(token)
+				if issuedAs == verifiedAs && err != nil {
+					t.Errorf("verify: %v, want nil", err)
+				}
+				if issuedAs != verifiedAs && err == nil {
+					t.Error("verify succeeded, want an error")
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestJWTManager(t, "RS256", dir, "https://issuer.test", time.Minute)
+	u := testUser()
+	valid, _ := m.IssueAccessToken(u, uuid.New())
+
+	expired, _ := m.issue(u.ID.String(), accessClaims{TokenUse: tokenUseAccess}, -time.Minute)
+	otherIssuer, _ := newTestJWTManager(t, "RS256", dir, "https://issuer.test/admin", time.Minute).IssueAccessToken(u, uuid.New())
+	otherKey, _ := newTestJWTManager(t, "RS256", t.TempDir(), "https://issuer.test", time.Minute).IssueAccessToken(u, uuid.New())
+
+	parts := strings.Split(valid.Token, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"sub":       u.ID.String(),
+		"iss":       "https://issuer.test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"token_use": tokenUseAccess,
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("signing unsigned token: %v", err)
+	}
+
+	// A token that only says what it is for in token_use, as tokens did
+	// before they carried an audience
+	noAudience, err := jwt.NewWithClaims(m.method, jwt.MapClaims{
+		"sub":       u.ID.String(),
+		"iss":       "https://issuer.test",
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"token_use": tokenUseAccess,
+	}).SignedString(m.keys.private)
+	if err != nil {
+		t.Fatalf("signing token without audience: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", expired.Token},
+		{"no audience", noAudience},
+		{"other issuer", otherIssuer.Token},
+		{"signed with another key", otherKey.Token},
+		{"tampered payload", tampered},
+		{"alg none", unsigned},
+		{"garbage", "not-a-token"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.VerifyAccessToken(tt.token); err == nil {
+				t.Error("VerifyAccessToken succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEmailVerificationTokenCarriesAddress(t *testing.T) {
+	m := newTestJWTManager(t, "EdDSA", t.TempDir(), "https://issuer.test", time.Minute)
+	u := testUser()
+
+	token, err := m.IssueEmailVerificationToken(u)
+	if err != nil {
+		t.Fatalf("IssueEmailVerificationToken: %v", err)
+	}
+	id, email, err := m.VerifyEmailVerificationToken(token)
+	if err != nil || id != u.ID || email != u.Email {
+		t.Errorf("VerifyEmailVerificationToken = %s, %q, %v; want %s, %q, nil", id, email, err, u.ID, u.Email)
+	}
+}
+
+func TestOpaqueToken(t *testing.T) {
+	a, err := NewOpaqueToken(time.Hour)
+	if err != nil {
+		t.Fatalf("NewOpaqueToken: %v", err)
+	}
+	b, _ := NewOpaqueToken(time.Hour)
+
+	if a.Token == b.Token {
+		t.Error("two opaque tokens are equal")
+	}
+	if a.Hash != HashOpaqueToken(a.Token) {
+		t.Error("Hash does not match HashOpaqueToken(Token)")
+	}
+	if a.Hash == a.Token {
+		t.Error("Hash equals the plain token")
+	}
+	if until := time.Until(a.ExpiresAt); until <= 59*time.Minute || until > time.Hour {
+		t.Errorf("ExpiresAt is %s away, want about an hour", until)
+	}
+}
@@ -0,0 +1,211 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+)
+
+const rsaKeyBits = 2048
+
+// keyPair holds the signing key and its public counterpart.
+type keyPair struct {
+	private crypto.Signer
+	public  crypto.PublicKey
+	kid     string
+}
+
+// loadKeyPair reads PEM encoded keys from disk. When generate is set and
+// neither file exists, a new key pair is created and written to the paths.
+func loadKeyPair(algorithm, privatePath, publicPath string, generate bool) (*keyPair, error) {
+	if generate && !fileExists(privatePath) && !fileExists(publicPath) {
+		if err := generateKeyPair(algorithm, privatePath, publicPath); err != nil {
+			return nil, fmt.Errorf("failed to generate %s key pair: %w", algorithm, err)
+		}
+	}
+
+	privPEM, err := os.ReadFile(privatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	pubPEM, err := os.ReadFile(publicPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	priv, err := parsePrivateKey(privPEM)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := parsePublicKey(pubPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case "RS256":
+		rsaPriv, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("RS256 requires an RSA private key")
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok || !rsaPriv.PublicKey.Equal(rsaPub) {
+			return nil, errors.New("RSA public key does not match private key")
+		}
+	case "EdDSA":
+		edPriv, ok := priv.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA requires an Ed25519 private key")
+		}
+		edPub, ok := pub.(ed25519.PublicKey)
+		if !ok || !edPub.Equal(edPriv.Public()) {
+			return nil, errors.New("Ed25519 public key does not match private key")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+
+	kp := &keyPair{private: priv, public: pub}
+	kp.kid = thumbprint(kp.jwk(algorithm))
+	return kp, nil
+}
+
+// jwk returns the public key in JSON Web Key format.
+func (k *keyPair) jwk(algorithm string) dto.JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return dto.JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: algorithm,
+			Kid: k.kid,
+			N:   b64(pub.N.Bytes()),
+			E:   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return dto.JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: algorithm,
+			Kid: k.kid,
+			Crv: "Ed25519",
+			X:   b64(pub),
+		}
+	}
+	return dto.JWK{}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint used as the key ID.
+func thumbprint(jwk dto.JWK) string {
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	raw, _ := json.Marshal(members)
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		return signer, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+func generateKeyPair(algorithm, privatePath, publicPath string) error {
+	var (
+		priv crypto.Signer
+		err  error
+	)
+	switch algorithm {
+	case "RS256":
+		priv, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case "EdDSA":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return err
+	}
+
+	if err := writePEM(privatePath, "PRIVATE KEY", privDER, 0600); err != nil {
+		return err
+	}
+	return writePEM(publicPath, "PUBLIC KEY", pubDER, 0644)
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,22 @@
+package token
+
+import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"token",
+	fx.Provide(
+		fx.Annotate(
+			ProvideJWTManager,
+			fx.As(new(auth.TokenManager)),
+			fx.As(new(user.VerificationTokens)),
+		),
+		fx.Annotate(
+			ProvideAdminJWTManager,
+			fx.As(new(auth.AdminTokenManager)),
+		),
+	),
+)
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+)
+
+const opaqueTokenBytes = 32
+
+// NewOpaqueToken generates a random URL-safe token valid for ttl. Only the
+// SHA-256 hash should be stored so a database leak does not expose tokens.
+func NewOpaqueToken(ttl time.Duration) (*auth.OpaqueToken, error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return &auth.OpaqueToken{
+		Token:     token,
+		Hash:      HashOpaqueToken(token),
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}, nil
+}
+
+// HashOpaqueToken returns the hex encoded SHA-256 hash of token.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	errorWriter
+	service rbac.AdminService
+}
+
+func NewAdminHandler(service rbac.AdminService, log logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		errorWriter: errorWriter{logger: log},
+		service:     service,
+	}
+}
+
+func (h *AdminHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.Login(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorLoginRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.LoginTwoFactor(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	req, parseErr := parseListUsersRequest(r)
+	if parseErr != nil {
+		response.WriteError(w, parseErr.Error(), http.StatusBadRequest)
+		return
+	}
+	res, meta, err := h.service.ListUsers(r.Context(), req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccessWithMeta(w, res, meta, http.StatusOK)
+}
+
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetUser(r.Context(), id)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) GetUserByEmail(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		response.WriteError(w, "email query parameter is required", http.StatusBadRequest)
+		return
+	}
+	res, err := h.service.GetUserByEmail(r.Context(), email)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.DisableUser(r.Context(), actorID, id, clientInfo(r))
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.EnableUser(r.Context(), actorID, id, clientInfo(r))
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.SetUserRolesRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.SetUserRoles(r.Context(), actorID, id, &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.ListRoles(r.Context())
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AdminHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	req, parseErr := parseListAuditLogRequest(r)
+	if parseErr != nil {
+		response.WriteError(w, parseErr.Error(), http.StatusBadRequest)
+		return
+	}
+	res, meta, err := h.service.ListAuditLog(r.Context(), req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccessWithMeta(w, res, meta, http.StatusOK)
+}
+
+// parseListUsersRequest reads the listing query parameters:
+// cursor, limit, email_prefix, created_from, created_to (RFC 3339), sort and order.
+func parseListUsersRequest(r *http.Request) (*dto.ListUsersRequest, error) {
+	q := r.URL.Query()
+	req := &dto.ListUsersRequest{
+		Cursor:      q.Get("cursor"),
+		EmailPrefix: q.Get("email_prefix"),
+		Sort:        q.Get("sort"),
+		Order:       q.Get("order"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		req.Limit = limit
+	}
+	for name, dst := range map[string]**time.Time{
+		"created_from": &req.CreatedFrom,
+		"created_to":   &req.CreatedTo,
+	} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		*dst = &t
+	}
+	return req, nil
+}
+
+// parseListAuditLogRequest reads the audit log query parameters:
+// cursor, limit, user_id, actor_id, from and to (RFC 3339).
+func parseListAuditLogRequest(r *http.Request) (*dto.ListAuditLogRequest, error) {
+	q := r.URL.Query()
+	req := &dto.ListAuditLogRequest{Cursor: q.Get("cursor")}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		req.Limit = limit
+	}
+	for name, dst := range map[string]**uuid.UUID{
+		"user_id":  &req.UserID,
+		"actor_id": &req.ActorID,
+	} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a UUID", name)
+		}
+		*dst = &id
+	}
+	for name, dst := range map[string]**time.Time{
+		"from": &req.From,
+		"to":   &req.To,
+	} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		*dst = &t
+	}
+	return req, nil
+}
+
+// userIDParam parses the {id} URL parameter. It writes a 400 response and
+// returns false when the parameter is not a valid UUID.
+func userIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, "invalid user id", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type AuthHandler struct {
+	errorWriter
+	service auth.AuthService
+}
+
+func NewAuthHandler(service auth.AuthService, log logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		errorWriter: errorWriter{logger: log},
+		service:     service,
+	}
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.Login(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorLoginRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.LoginTwoFactor(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.Refresh(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	sessionID, ok := currentSessionID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.ListSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, parseErr := uuid.Parse(chi.URLParam(r, "id"))
+	if parseErr != nil {
+		response.WriteError(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.RevokeSession(r.Context(), userID, id); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	sessionID, ok := currentSessionID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.RevokeOtherSessions(r.Context(), userID, sessionID); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS serves the public signing keys as a bare JSON Web Key Set so that
+// standard JWT libraries can consume it directly.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.service.JWKS()); err != nil {
+		response.WriteError(w, "failed to encode jwks", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/balance"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/google/uuid"
+)
+
+type BalanceHandler struct {
+	errorWriter
+	service balance.BalanceService
+}
+
+func NewBalanceHandler(service balance.BalanceService, log logger.Logger) *BalanceHandler {
+	return &BalanceHandler{
+		errorWriter: errorWriter{logger: log},
+		service:     service,
+	}
+}
+
+func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetBalance(r.Context(), id)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *BalanceHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	req := &dto.ListTransactionsRequest{Cursor: r.URL.Query().Get("cursor")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, parseErr := strconv.Atoi(v)
+		if parseErr != nil || limit < 1 {
+			response.WriteError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		req.Limit = limit
+	}
+	res, meta, err := h.service.ListTransactions(r.Context(), id, req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccessWithMeta(w, res, meta, http.StatusOK)
+}
+
+// Credit adds funds to the balance of the user in the URL on behalf of the
+// calling admin.
+func (h *BalanceHandler) Credit(w http.ResponseWriter, r *http.Request) {
+	h.adjust(w, r, h.service.Credit)
+}
+
+// Debit removes funds from the balance of the user in the URL on behalf of
+// the calling admin.
+func (h *BalanceHandler) Debit(w http.ResponseWriter, r *http.Request) {
+	h.adjust(w, r, h.service.Debit)
+}
+
+func (h *BalanceHandler) adjust(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, actorID, userID uuid.UUID, req *dto.LedgerOperationRequest) (*dto.LedgerEntryDetail, *shared.DomainError)) {
+	actorID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.LedgerOperationRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := op(r.Context(), actorID, id, &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusCreated)
+}
@@ -6,5 +6,9 @@ var Module = fx.Module(
 	"handler",
 	fx.Provide(
 		NewUserHandler,
+		NewAuthHandler,
+		NewOAuthHandler,
+		NewBalanceHandler,
+		NewAdminHandler,
 	),
 )
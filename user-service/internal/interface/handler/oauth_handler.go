@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/auth"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+const oauthStateCookie = "oauth_state"
+
+type OAuthHandler struct {
+	errorWriter
+	service     auth.AuthService
+	frontendURL string
+	secure      bool
+}
+
+func NewOAuthHandler(service auth.AuthService, cfg *config.Config, log logger.Logger) *OAuthHandler {
+	frontendURL := cfg.Auth.OAuth.FrontendURL
+	if frontendURL == "" {
+		frontendURL = cfg.App.FrontendURL
+	}
+	return &OAuthHandler{
+		errorWriter: errorWriter{logger: log},
+		service:     service,
+		frontendURL: frontendURL,
+		secure:      strings.HasPrefix(cfg.App.URL, "https://"),
+	}
+}
+
+// Redirect starts the authorization-code flow and redirects to the provider.
+// The state is also stored in a cookie so the callback can verify it comes
+// from the same browser.
+func (h *OAuthHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.StartOAuth(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		h.redirectError(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, h.stateCookie(res.State, int(res.ExpiresIn)))
+	http.Redirect(w, r, res.URL, http.StatusFound)
+}
+
+// Link starts the authorization-code flow for linking a provider to the
+// signed-in user. It is called with the access token, so the provider URL is
+// returned for the frontend to navigate to rather than redirected to.
+func (h *OAuthHandler) Link(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.StartOAuthLink(r.Context(), id, chi.URLParam(r, "provider"))
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, h.stateCookie(res.State, int(res.ExpiresIn)))
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+// Callback handles the provider redirect (query or form_post) and hands the
+// session back to the frontend in the URL fragment.
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// The state cookie is single use
+	http.SetCookie(w, h.stateCookie("", -1))
+
+	if providerErr := r.FormValue("error"); providerErr != "" {
+		h.redirectError(w, r, shared.NewDomainError("OAUTH_DENIED", 400, providerErr))
+		return
+	}
+
+	state := r.FormValue("state")
+	cookie, cookieErr := r.Cookie(oauthStateCookie)
+	if cookieErr != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		h.redirectError(w, r, shared.NewDomainError("INVALID_OAUTH_STATE", 400, "oauth state is invalid or was already used"))
+		return
+	}
+
+	res, err := h.service.CompleteOAuth(r.Context(), chi.URLParam(r, "provider"), state, r.FormValue("code"), clientInfo(r))
+	if err != nil {
+		h.redirectError(w, r, err)
+		return
+	}
+	h.redirectSuccess(w, r, res)
+}
+
+func (h *OAuthHandler) redirectSuccess(w http.ResponseWriter, r *http.Request, res *dto.LoginResponse) {
+	fragment := url.Values{}
+	if res.Linked {
+		fragment.Set("linked", chi.URLParam(r, "provider"))
+	} else if res.MFARequired {
+		fragment.Set("mfa_required", "true")
+		fragment.Set("mfa_token", res.MFAToken)
+	} else {
+		fragment.Set("access_token", res.AccessToken)
+		fragment.Set("token_type", res.TokenType)
+		fragment.Set("expires_in", strconv.FormatInt(res.ExpiresIn, 10))
+		fragment.Set("refresh_token", res.RefreshToken)
+		fragment.Set("refresh_expires_in", strconv.FormatInt(res.RefreshExpiresIn, 10))
+	}
+	h.redirectFrontend(w, r, fragment)
+}
+
+func (h *OAuthHandler) redirectError(w http.ResponseWriter, r *http.Request, err *shared.DomainError) {
+	h.logServerError(r, err)
+	fragment := url.Values{}
+	fragment.Set("error", err.Code)
+	fragment.Set("error_description", err.Message)
+	h.redirectFrontend(w, r, fragment)
+}
+
+// redirectFrontend uses the fragment so tokens never reach server logs or
+// Referer headers.
+func (h *OAuthHandler) redirectFrontend(w http.ResponseWriter, r *http.Request, fragment url.Values) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, h.frontendURL+"#"+fragment.Encode(), http.StatusFound)
+}
+
+func (h *OAuthHandler) stateCookie(value string, maxAge int) *http.Cookie {
+	c := &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    value,
+		Path:     "/api/v1/auth/oauth",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	// Providers using response_mode=form_post (Apple) call back with a
+	// cross-site POST, which only carries SameSite=None cookies.
+	if h.secure {
+		c.SameSite = http.SameSiteNoneMode
+	}
+	return c
+}
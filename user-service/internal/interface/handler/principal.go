@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
+	"github.com/Nezent/microservice-template/user-service/internal/domain/shared"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// currentUserID returns the ID of the authenticated user. It writes a 401
+// response and returns false when the request is not authenticated.
+func currentUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	principal, ok := router.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, "authentication required", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		response.WriteError(w, "invalid principal", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// currentSessionID returns the session the authenticated user's access token
+// belongs to. It writes a 401 response and returns false when there is none.
+func currentSessionID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	principal, ok := router.PrincipalFromContext(r.Context())
+	if !ok {
+		response.WriteError(w, "authentication required", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(principal.SessionID)
+	if err != nil {
+		response.WriteError(w, "invalid principal", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// clientInfo describes the device the request came from.
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:        router.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// errorWriter writes domain errors for the handlers that embed it. The cause
+// of an error is never sent to clients, so it is logged for server errors.
+type errorWriter struct {
+	logger logger.Logger
+}
+
+// writeDomainError writes err with its code so clients can branch on it.
+func (e errorWriter) writeDomainError(w http.ResponseWriter, r *http.Request, err *shared.DomainError) {
+	e.logServerError(r, err)
+	if err.RetryAfter > 0 {
+		seconds := int64(math.Ceil(err.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	response.WriteErrorCode(w, err.Code, err.Message, err.StatusCode)
+}
+
+// logServerError logs err with its cause and the request ID if it is a
+// server error. Client errors are expected and not logged.
+func (e errorWriter) logServerError(r *http.Request, err *shared.DomainError) {
+	if err.StatusCode < http.StatusInternalServerError {
+		return
+	}
+	e.logger.Error("request failed",
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("code", err.Code),
+		zap.Error(err.Cause),
+	)
+}
@@ -1,44 +1,231 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/Nezent/microservice-template/user-service/internal/application/dto"
 	"github.com/Nezent/microservice-template/user-service/internal/domain/user"
+	"github.com/Nezent/microservice-template/user-service/internal/infrastructure/logger"
 	"github.com/Nezent/microservice-template/user-service/pkg/response"
 )
 
 type UserHandler struct {
+	errorWriter
 	service user.UserService
 }
 
-func NewUserHandler(service user.UserService) *UserHandler {
+func NewUserHandler(service user.UserService, log logger.Logger) *UserHandler {
 	return &UserHandler{
-		service: service,
+		errorWriter: errorWriter{logger: log},
+		service:     service,
 	}
 }
 
 // Implement handler methods here
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.WriteError(w, err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, defaultBodyLimit) {
 		return
 	}
-	res, err := h.service.CreateUser(&req)
+	req.Client = clientInfo(r)
+	res, err := h.service.CreateUser(r.Context(), &req)
 	if err != nil {
-		response.WriteError(w, err.Error(), err.StatusCode)
+		h.writeDomainError(w, r, err)
 		return
 	}
 
 	response.WriteSuccess(w, res, http.StatusCreated)
 }
 
-func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	res, err := h.service.GetUser()
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.UpdateProfileRequest
+	if !decodeJSON(w, r, &req, defaultBodyLimit) {
+		return
+	}
+	res, err := h.service.UpdateProfile(r.Context(), id, &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) ChangeMyEmail(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.ChangeEmailRequest
+	if !decodeJSON(w, r, &req, defaultBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.ChangeEmail(r.Context(), id, &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	res, err := h.service.VerifyEmail(r.Context(), &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.ResendVerificationEmail(r.Context(), id, clientInfo(r)); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *UserHandler) ChangeMyPassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	if err := h.service.ChangePassword(r.Context(), id, &req); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	if err := h.service.RequestPasswordReset(r.Context(), &req); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	if err := h.service.ResetPassword(r.Context(), &req); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteUser(r.Context(), id, clientInfo(r)); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.EnrollTOTP(r.Context(), id)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.TOTPCodeRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.ConfirmTOTP(r.Context(), id, &req)
+	if err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	response.WriteSuccess(w, res, http.StatusOK)
+}
+
+func (h *UserHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.TOTPChangeRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	if err := h.service.DisableTOTP(r.Context(), id, &req); err != nil {
+		h.writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.TOTPChangeRequest
+	if !decodeJSON(w, r, &req, smallBodyLimit) {
+		return
+	}
+	req.Client = clientInfo(r)
+	res, err := h.service.RegenerateRecoveryCodes(r.Context(), id, &req)
 	if err != nil {
-		response.WriteError(w, err.Error(), err.StatusCode)
+		h.writeDomainError(w, r, err)
 		return
 	}
 	response.WriteSuccess(w, res, http.StatusOK)
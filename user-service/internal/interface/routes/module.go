@@ -1,10 +1,24 @@
 package routes
 
-import "go.uber.org/fx"
+import (
+	"github.com/Nezent/microservice-template/user-service/config"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
+	"go.uber.org/fx"
+)
 
 var Module = fx.Module(
 	"routes",
 	fx.Provide(
 		NewRoutes,
+		ProvideClientIPMiddleware,
 	),
 )
+
+// ProvideClientIPMiddleware trusts the proxies listed in app.trusted_proxies.
+func ProvideClientIPMiddleware(cfg *config.Config) (*router.ClientIPMiddleware, error) {
+	trusted, err := cfg.App.TrustedProxyPrefixes()
+	if err != nil {
+		return nil, err
+	}
+	return router.NewClientIPMiddleware(trusted), nil
+}
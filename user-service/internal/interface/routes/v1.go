@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"github.com/Nezent/microservice-template/user-service/internal/domain/rbac"
 	"github.com/Nezent/microservice-template/user-service/internal/interface/handler"
+	"github.com/Nezent/microservice-template/user-service/pkg/router"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/fx"
 )
@@ -9,28 +11,144 @@ import (
 type APIV1RoutesParams struct {
 	fx.In
 
-	Router      *chi.Mux
-	UserHandler *handler.UserHandler
+	Router         *chi.Mux
+	AuthMiddleware *router.AuthMiddleware
+	AdminAuth      *router.AuthMiddleware `name:"admin"`
+	Idempotency    *router.IdempotencyMiddleware
+	UserHandler    *handler.UserHandler
+	AuthHandler    *handler.AuthHandler
+	OAuthHandler   *handler.OAuthHandler
+	BalanceHandler *handler.BalanceHandler
+	AdminHandler   *handler.AdminHandler
 }
 
 type APIV1Routes struct {
-	router      *chi.Mux
-	userHandler *handler.UserHandler
+	router         *chi.Mux
+	authMiddleware *router.AuthMiddleware
+	adminAuth      *router.AuthMiddleware
+	idempotency    *router.IdempotencyMiddleware
+	userHandler    *handler.UserHandler
+	authHandler    *handler.AuthHandler
+	oauthHandler   *handler.OAuthHandler
+	balanceHandler *handler.BalanceHandler
+	adminHandler   *handler.AdminHandler
 }
 
 func NewRoutes(params APIV1RoutesParams) *APIV1Routes {
 	return &APIV1Routes{
-		router:      params.Router,
-		userHandler: params.UserHandler,
+		router:         params.Router,
+		authMiddleware: params.AuthMiddleware,
+		adminAuth:      params.AdminAuth,
+		idempotency:    params.Idempotency,
+		userHandler:    params.UserHandler,
+		authHandler:    params.AuthHandler,
+		oauthHandler:   params.OAuthHandler,
+		balanceHandler: params.BalanceHandler,
+		adminHandler:   params.AdminHandler,
 	}
 }
 
 func (r *APIV1Routes) Register() {
+	// Well-known location for JWT verifiers such as the gateway
+	r.router.Get("/.well-known/jwks.json", r.authHandler.JWKS)
+
 	r.router.Route("/api/v1", func(v1 chi.Router) {
 		// guest routes
 		v1.Route("/auth", func(noAuth chi.Router) {
-			// noAuth.Post("/login", r.userHandler.Login)
-			noAuth.Post("/register", r.userHandler.Register)
+			noAuth.With(router.NoStore).Post("/login", r.authHandler.Login)
+			noAuth.With(router.NoStore).Post("/login/2fa", r.authHandler.LoginTwoFactor)
+			noAuth.With(r.idempotency.Handle).Post("/register", r.userHandler.Register)
+			noAuth.With(router.NoStore).Post("/refresh", r.authHandler.Refresh)
+			noAuth.Post("/verify-email", r.userHandler.VerifyEmail)
+			noAuth.Post("/password/forgot", r.userHandler.ForgotPassword)
+			noAuth.Post("/password/reset", r.userHandler.ResetPassword)
+			noAuth.Get("/jwks", r.authHandler.JWKS)
+
+			noAuth.Route("/oauth/{provider}", func(oauth chi.Router) {
+				oauth.Get("/", r.oauthHandler.Redirect)
+				oauth.Get("/callback", r.oauthHandler.Callback)
+				oauth.Post("/callback", r.oauthHandler.Callback)
+			})
+		})
+
+		// authenticated routes
+		v1.Group(func(authed chi.Router) {
+			authed.Use(r.authMiddleware.Authenticate)
+
+			// Users only see their own record; looking up other users is
+			// part of the admin API.
+			authed.Route("/users", func(users chi.Router) {
+				// Profile and email management stay available to unverified
+				// users so they can fix a mistyped address.
+				users.Route("/me", func(me chi.Router) {
+					me.Get("/", r.userHandler.Me)
+					me.Patch("/", r.userHandler.UpdateMe)
+					me.Delete("/", r.userHandler.DeleteMe)
+					me.Put("/email", r.userHandler.ChangeMyEmail)
+					me.Post("/email/verification", r.userHandler.ResendVerificationEmail)
+					me.Put("/password", r.userHandler.ChangeMyPassword)
+					me.With(router.NoStore).Post("/oauth/{provider}", r.oauthHandler.Link)
+
+					me.Route("/sessions", func(sessions chi.Router) {
+						sessions.Get("/", r.authHandler.ListSessions)
+						// Signs out every other device
+						sessions.Delete("/", r.authHandler.RevokeOtherSessions)
+						sessions.Delete("/{id}", r.authHandler.RevokeSession)
+					})
+
+					me.Route("/2fa", func(twoFactor chi.Router) {
+						twoFactor.Use(router.RequireVerifiedEmail)
+						// Enrollment returns the secret and recovery codes
+						twoFactor.Use(router.NoStore)
+
+						twoFactor.Post("/enroll", r.userHandler.EnrollTOTP)
+						twoFactor.Post("/confirm", r.userHandler.ConfirmTOTP)
+						twoFactor.Post("/disable", r.userHandler.DisableTOTP)
+						twoFactor.Post("/recovery-codes", r.userHandler.RegenerateRecoveryCodes)
+					})
+				})
+			})
+
+			authed.Route("/balance", func(bal chi.Router) {
+				bal.Use(router.RequireVerifiedEmail)
+				bal.Get("/", r.balanceHandler.GetBalance)
+				bal.Get("/transactions", r.balanceHandler.ListTransactions)
+			})
+		})
+
+		// admin routes, authenticated with admin tokens only
+		v1.Route("/admin", func(admin chi.Router) {
+			admin.Route("/auth", func(noAuth chi.Router) {
+				noAuth.Use(router.NoStore)
+
+				noAuth.Post("/login", r.adminHandler.Login)
+				noAuth.Post("/login/2fa", r.adminHandler.LoginTwoFactor)
+			})
+
+			admin.Group(func(authed chi.Router) {
+				authed.Use(r.adminAuth.Authenticate)
+
+				authed.Route("/users", func(users chi.Router) {
+					users.With(router.RequirePermission(rbac.PermUsersRead)).Get("/", r.adminHandler.ListUsers)
+					users.With(router.RequirePermission(rbac.PermUsersRead)).Get("/by-email", r.adminHandler.GetUserByEmail)
+					users.With(router.RequirePermission(rbac.PermUsersRead)).Get("/{id}", r.adminHandler.GetUser)
+					users.With(router.RequirePermission(rbac.PermUsersDisable)).Post("/{id}/disable", r.adminHandler.DisableUser)
+					users.With(router.RequirePermission(rbac.PermUsersDisable)).Post("/{id}/enable", r.adminHandler.EnableUser)
+					users.With(router.RequirePermission(rbac.PermRolesAssign)).Put("/{id}/roles", r.adminHandler.SetUserRoles)
+
+					// Balance adjustments are safe to retry with an
+					// Idempotency-Key. Keys are scoped per admin, so this
+					// runs after authentication.
+					users.Route("/{id}/balance", func(bal chi.Router) {
+						bal.Use(router.RequirePermission(rbac.PermBalanceAdjust), r.idempotency.Handle)
+						bal.Post("/credit", r.balanceHandler.Credit)
+						bal.Post("/debit", r.balanceHandler.Debit)
+					})
+				})
+
+				authed.With(router.RequirePermission(rbac.PermRolesRead)).Get("/roles", r.adminHandler.ListRoles)
+				authed.With(router.RequirePermission(rbac.PermAuditRead)).Get("/audit-log", r.adminHandler.ListAuditLog)
+			})
 		})
 	})
 }
@@ -12,5 +12,4 @@ CREATE TABLE balance (
 -- +goose Down
 -- +goose StatementBegin
 DROP TABLE IF EXISTS balance;
-DROP CONSTRAINT IF EXISTS fk_user ON balance;
 -- +goose StatementEnd
@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+-- balance becomes the ledger account table. User accounts hold a running
+-- balance in minor units that may never go negative; system accounts are the
+-- counterparties of external credits and debits.
+
+-- Each user gets a single account: fold any extra rows into the oldest one.
+CREATE TEMPORARY TABLE balance_ranked ON COMMIT DROP AS
+SELECT id,
+       ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at NULLS LAST, id) AS position,
+       SUM(amount) OVER (PARTITION BY user_id) AS total
+FROM balance;
+UPDATE balance b SET amount = r.total FROM balance_ranked r WHERE b.id = r.id AND r.position = 1;
+DELETE FROM balance b USING balance_ranked r WHERE b.id = r.id AND r.position > 1;
+
+ALTER TABLE balance ALTER COLUMN user_id DROP NOT NULL;
+-- Amounts were stored in major units
+ALTER TABLE balance ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+ALTER TABLE balance ALTER COLUMN amount SET DEFAULT 0;
+ALTER TABLE balance ADD COLUMN IF NOT EXISTS system_code VARCHAR(50);
+ALTER TABLE balance ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE balance ADD CONSTRAINT uq_balance_user_id UNIQUE (user_id);
+ALTER TABLE balance ADD CONSTRAINT uq_balance_system_code UNIQUE (system_code);
+ALTER TABLE balance ADD CONSTRAINT chk_balance_owner CHECK ((user_id IS NULL) <> (system_code IS NULL));
+ALTER TABLE balance ADD CONSTRAINT chk_balance_non_negative CHECK (user_id IS NULL OR amount >= 0);
+
+INSERT INTO balance (system_code, amount) VALUES ('external', 0);
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(20) NOT NULL,
+    reference VARCHAR(100),
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_ledger_transactions_reference UNIQUE (reference)
+);
+
+-- Every transaction posts entries that sum to zero. balance_after is only
+-- tracked for user accounts.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    amount BIGINT NOT NULL,
+    balance_after BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_ledger_entries_transaction FOREIGN KEY(transaction_id) REFERENCES ledger_transactions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ledger_entries_account FOREIGN KEY(account_id) REFERENCES balance(id) ON DELETE CASCADE,
+    CONSTRAINT chk_ledger_entries_amount CHECK (amount <> 0)
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_created_at ON ledger_entries(account_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DELETE FROM balance WHERE system_code IS NOT NULL;
+ALTER TABLE balance DROP CONSTRAINT IF EXISTS chk_balance_non_negative;
+ALTER TABLE balance DROP CONSTRAINT IF EXISTS chk_balance_owner;
+ALTER TABLE balance DROP CONSTRAINT IF EXISTS uq_balance_system_code;
+ALTER TABLE balance DROP CONSTRAINT IF EXISTS uq_balance_user_id;
+ALTER TABLE balance DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE balance DROP COLUMN IF EXISTS system_code;
+ALTER TABLE balance ALTER COLUMN amount DROP DEFAULT;
+ALTER TABLE balance ALTER COLUMN amount TYPE NUMERIC USING amount::NUMERIC / 100;
+ALTER TABLE balance ALTER COLUMN user_id SET NOT NULL;
+-- +goose StatementEnd
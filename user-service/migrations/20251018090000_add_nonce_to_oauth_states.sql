@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pending authorization requests have no nonce and cannot complete anymore.
+DELETE FROM oauth_states;
+ALTER TABLE oauth_states ADD COLUMN IF NOT EXISTS nonce VARCHAR(64) NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS nonce;
+-- +goose StatementEnd
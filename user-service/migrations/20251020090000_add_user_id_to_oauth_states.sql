@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set when a signed-in user links a provider instead of signing in with it.
+ALTER TABLE oauth_states ADD COLUMN IF NOT EXISTS user_id UUID
+    CONSTRAINT fk_oauth_states_user REFERENCES users(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
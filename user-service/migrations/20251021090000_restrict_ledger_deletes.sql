@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ledger history is never deleted implicitly. Deleting a user with a
+-- balance account cascades to the account, which these keys then refuse
+-- once it has entries.
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS fk_ledger_entries_transaction;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_ledger_entries_transaction
+    FOREIGN KEY(transaction_id) REFERENCES ledger_transactions(id) ON DELETE RESTRICT;
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS fk_ledger_entries_account;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_ledger_entries_account
+    FOREIGN KEY(account_id) REFERENCES balance(id) ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS fk_ledger_entries_account;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_ledger_entries_account
+    FOREIGN KEY(account_id) REFERENCES balance(id) ON DELETE CASCADE;
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS fk_ledger_entries_transaction;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_ledger_entries_transaction
+    FOREIGN KEY(transaction_id) REFERENCES ledger_transactions(id) ON DELETE CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so that the service binary can
+// apply them without the goose CLI.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -7,10 +7,20 @@ import (
 
 // APIResponse is a standard response structure.
 type APIResponse struct {
-	Success    bool   `json:"success"`
-	StatusCode int    `json:"status_code"`
-	Data       any    `json:"data,omitempty"`
-	Error      string `json:"error,omitempty"`
+	Success    bool         `json:"success"`
+	StatusCode int          `json:"status_code"`
+	Data       any          `json:"data,omitempty"`
+	Meta       any          `json:"meta,omitempty"`
+	Code       string       `json:"code,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Errors     []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // WriteSuccess writes a successful response with data.
@@ -27,6 +37,22 @@ func WriteSuccess(w http.ResponseWriter, data any, statusCode int) {
 	}
 }
 
+// WriteSuccessWithMeta writes a successful response with data and metadata
+// such as pagination cursors.
+func WriteSuccessWithMeta(w http.ResponseWriter, data any, meta any, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(APIResponse{
+		Success:    true,
+		StatusCode: statusCode,
+		Data:       data,
+		Meta:       meta,
+	})
+	if err != nil {
+		http.Error(w, `{"success":false,"status_code":500,"error":"Internal Server Error"}`, http.StatusInternalServerError)
+	}
+}
+
 // WriteError writes an error response.
 func WriteError(w http.ResponseWriter, errMsg string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -40,3 +66,35 @@ func WriteError(w http.ResponseWriter, errMsg string, statusCode int) {
 		http.Error(w, `{"success":false,"status_code":500,"error":"Internal Server Error"}`, http.StatusInternalServerError)
 	}
 }
+
+// WriteErrorCode writes an error response carrying a stable, machine-readable
+// code that clients can branch on.
+func WriteErrorCode(w http.ResponseWriter, code string, errMsg string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(APIResponse{
+		Success:    false,
+		StatusCode: statusCode,
+		Code:       code,
+		Error:      errMsg,
+	})
+	if err != nil {
+		http.Error(w, `{"success":false,"status_code":500,"error":"Internal Server Error"}`, http.StatusInternalServerError)
+	}
+}
+
+// WriteValidationError writes a 422 response listing the rejected fields.
+func WriteValidationError(w http.ResponseWriter, errMsg string, fields []FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	err := json.NewEncoder(w).Encode(APIResponse{
+		Success:    false,
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       "VALIDATION_FAILED",
+		Error:      errMsg,
+		Errors:     fields,
+	})
+	if err != nil {
+		http.Error(w, `{"success":false,"status_code":500,"error":"Internal Server Error"}`, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,147 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+)
+
+// Principal is the authenticated caller attached to the request context.
+type Principal struct {
+	UserID        string
+	Email         string
+	EmailVerified bool
+	TokenID       string
+	SessionID     string
+	ExpiresAt     time.Time
+	// Permissions is only set for admin principals.
+	Permissions []string
+}
+
+// TokenVerifier validates a bearer token and returns the principal it was issued to.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the auth middleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// AuthMiddleware authenticates requests carrying a bearer token.
+type AuthMiddleware struct {
+	verifier TokenVerifier
+}
+
+func NewAuthMiddleware(verifier TokenVerifier) *AuthMiddleware {
+	return &AuthMiddleware{
+		verifier: verifier,
+	}
+}
+
+// Authenticate rejects requests without a valid bearer token with 401 and
+// stores the authenticated principal in the request context.
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, "missing bearer token")
+			return
+		}
+
+		principal, err := m.verifier.VerifyToken(r.Context(), token)
+		if err != nil {
+			unauthorized(w, "invalid or expired token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Authorize rejects authenticated requests for which allow returns false
+// with 403. It must be mounted after Authenticate.
+func Authorize(allow func(p *Principal, r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				unauthorized(w, "authentication required")
+				return
+			}
+			if !allow(principal, r) {
+				response.WriteError(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireVerifiedEmail rejects callers whose email is not verified with 403.
+// It must be mounted after Authenticate.
+func RequireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			unauthorized(w, "authentication required")
+			return
+		}
+		if !principal.EmailVerified {
+			response.WriteErrorCode(w, "EMAIL_NOT_VERIFIED", "email address must be verified", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission rejects callers whose principal lacks permission with
+// 403. It must be mounted after Authenticate.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				unauthorized(w, "authentication required")
+				return
+			}
+			if !principal.HasPermission(permission) {
+				response.WriteErrorCode(w, "PERMISSION_DENIED", "missing permission "+permission, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasPermission reports whether the principal was granted permission.
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	response.WriteError(w, msg, http.StatusUnauthorized)
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ClientIPMiddleware resolves the address of the client once per request.
+// The X-Real-IP header is only honoured on connections from a trusted
+// proxy such as nginx; anyone else could set it to pick any address they
+// like. X-Forwarded-For is always ignored because clients can append to it.
+type ClientIPMiddleware struct {
+	trusted []netip.Prefix
+}
+
+func NewClientIPMiddleware(trustedProxies []netip.Prefix) *ClientIPMiddleware {
+	return &ClientIPMiddleware{
+		trusted: trustedProxies,
+	}
+}
+
+func (m *ClientIPMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := m.resolve(r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+	})
+}
+
+func (m *ClientIPMiddleware) resolve(r *http.Request) string {
+	peer := remoteIP(r)
+	if !containsIP(m.trusted, peer) {
+		return peer
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return peer
+}
+
+// containsIP reports whether ip lies in one of prefixes.
+func containsIP(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client as resolved by
+// ClientIPMiddleware, or the address of the peer if it did not run.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
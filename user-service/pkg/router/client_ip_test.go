@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	m := NewClientIPMiddleware([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		want       string
+	}{
+		{"trusted proxy", "10.1.2.3:4000", "203.0.113.7", "203.0.113.7"},
+		{"trusted proxy without header", "10.1.2.3:4000", "", "10.1.2.3"},
+		{"trusted proxy with invalid header", "10.1.2.3:4000", "not-an-ip", "10.1.2.3"},
+		{"spoofed header from a client", "198.51.100.9:4000", "203.0.113.7", "198.51.100.9"},
+		{"ipv4-mapped proxy address", "[::ffff:10.1.2.3]:4000", "203.0.113.7", "203.0.113.7"},
+		{"client without header", "198.51.100.9:4000", "", "198.51.100.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			var got string
+			m.Handle(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Without the middleware the header is never trusted.
+func TestClientIPWithoutMiddleware(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "198.51.100.9:4000"
+	r.Header.Set("X-Real-IP", "203.0.113.7")
+
+	if got := ClientIP(r); got != "198.51.100.9" {
+		t.Errorf("ClientIP = %q, want the peer address", got)
+	}
+}
@@ -0,0 +1,213 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses served from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxStoredResponseBody   = 1 << 20
+)
+
+// replayedHeaders are the response headers stored with a response. Encoding
+// headers are left out because the replay passes through the compressor again.
+var replayedHeaders = []string{"Content-Type", "Location", "Cache-Control"}
+
+// IdempotencyRecord is the state stored for an idempotency key. StatusCode is
+// zero while the original request is still being processed.
+type IdempotencyRecord struct {
+	Fingerprint string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore persists idempotency keys and the responses they produced.
+type IdempotencyStore interface {
+	// Reserve atomically claims key for a request with the given fingerprint
+	// for lockTTL. If the key is already taken it returns false and the
+	// existing record.
+	Reserve(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete stores the response for a reserved key for ttl.
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release frees a reserved key that has no stored response.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddleware makes mutating requests that carry an Idempotency-Key
+// header safe to retry: the first response is stored and replayed for
+// retries, and reusing a key with a different payload is rejected.
+type IdempotencyMiddleware struct {
+	store   IdempotencyStore
+	secret  []byte
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// NewIdempotencyMiddleware stores responses for ttl. lockTTL bounds how long a
+// key stays locked if the instance processing it dies before completing.
+// secret keys the request fingerprints, since request bodies may contain
+// passwords.
+func NewIdempotencyMiddleware(store IdempotencyStore, secret []byte, ttl, lockTTL time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		store:   store,
+		secret:  secret,
+		ttl:     ttl,
+		lockTTL: lockTTL,
+	}
+}
+
+// Handle applies idempotency to POST, PUT, PATCH and DELETE requests that
+// carry the header. Keys are scoped to the authenticated principal, so mount
+// it after Authenticate on protected routes. Responses marked with
+// Cache-Control: no-store are never stored.
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.WriteErrorCode(w, "IDEMPOTENCY_KEY_INVALID", "idempotency key must not exceed 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				response.WriteError(w, "request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			response.WriteError(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		storeKey := scopedKey(r, key)
+		fingerprint := m.fingerprint(r, body)
+
+		existing, reserved, err := m.store.Reserve(ctx, storeKey, fingerprint, m.lockTTL)
+		if err != nil {
+			response.WriteErrorCode(w, "IDEMPOTENCY_UNAVAILABLE", "idempotency store is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if !reserved {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				response.WriteErrorCode(w, "IDEMPOTENCY_KEY_REUSED", "idempotency key was already used with a different request", http.StatusUnprocessableEntity)
+			case existing.StatusCode == 0:
+				w.Header().Set("Retry-After", "1")
+				response.WriteErrorCode(w, "IDEMPOTENCY_REQUEST_IN_PROGRESS", "a request with this idempotency key is still being processed", http.StatusConflict)
+			default:
+				replay(w, existing)
+			}
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		var buf bytes.Buffer
+		ww.Tee(&buf)
+
+		completed := false
+		defer func() {
+			if !completed {
+				// The handler panicked; free the key so the client can retry.
+				_ = m.store.Release(context.WithoutCancel(ctx), storeKey)
+			}
+		}()
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		completed = true
+		// Server errors and oversized bodies are not stored so a retry
+		// re-executes the request. Neither are responses marked no-store,
+		// which carry credentials that must not outlive the response.
+		if status >= http.StatusInternalServerError || buf.Len() > maxStoredResponseBody || isNoStore(ww.Header()) {
+			_ = m.store.Release(context.WithoutCancel(ctx), storeKey)
+			return
+		}
+
+		header := make(http.Header)
+		for _, name := range replayedHeaders {
+			if v := ww.Header().Values(name); len(v) > 0 {
+				header[name] = v
+			}
+		}
+		_ = m.store.Complete(context.WithoutCancel(ctx), storeKey, &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			StatusCode:  status,
+			Header:      header,
+			Body:        buf.Bytes(),
+		}, m.ttl)
+	})
+}
+
+func replay(w http.ResponseWriter, record *IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// scopedKey namespaces the client's key by caller so different users cannot
+// collide. The route is part of the fingerprint instead, so reusing a key on
+// another endpoint is rejected rather than silently accepted.
+func scopedKey(r *http.Request, key string) string {
+	subject := ""
+	if p, ok := PrincipalFromContext(r.Context()); ok {
+		subject = p.UserID
+	}
+	sum := sha256.Sum256([]byte(subject + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// fingerprint is an HMAC rather than a plain hash of the request, so that a
+// stored fingerprint cannot be used to guess a password in the body offline.
+func (m *IdempotencyMiddleware) fingerprint(r *http.Request, body []byte) string {
+	h := hmac.New(sha256.New, m.secret)
+	h.Write([]byte(r.Method + "\x00" + r.URL.RequestURI() + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isNoStore(header http.Header) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testIdempotencySecret = []byte("test-idempotency-secret")
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore without expiry.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]*IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, _ time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[key]; ok {
+		return existing, false, nil
+	}
+	s.records[key] = &IdempotencyRecord{Fingerprint: fingerprint}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && record.StatusCode == 0 {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// countingHandler responds with status and the number of calls so far.
+type countingHandler struct {
+	calls  int
+	status int
+	header http.Header
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	for name, values := range h.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(h.calls) + `}`))
+}
+
+type idempotentRequest struct {
+	method string
+	path   string
+	key    string
+	body   string
+	userID string
+}
+
+func (req idempotentRequest) send(handler http.Handler) *httptest.ResponseRecorder {
+	method, path := req.method, req.path
+	if method == "" {
+		method = http.MethodPost
+	}
+	if path == "" {
+		path = "/transfers"
+	}
+	r := httptest.NewRequest(method, path, strings.NewReader(req.body))
+	if req.key != "" {
+		r.Header.Set(IdempotencyKeyHeader, req.key)
+	}
+	if req.userID != "" {
+		r = r.WithContext(WithPrincipal(r.Context(), &Principal{UserID: req.userID}))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	first := idempotentRequest{key: "key-1", body: `{"amount":1}`, userID: "alice"}
+
+	tests := []struct {
+		name string
+		// status and header are returned by the handler
+		status int
+		header http.Header
+		// second is sent after first
+		second     idempotentRequest
+		wantStatus int
+		wantCalls  int
+		wantReplay bool
+	}{
+		{
+			name: "retry replays the stored response", status: http.StatusCreated,
+			second:     first,
+			wantStatus: http.StatusCreated, wantCalls: 1, wantReplay: true,
+		},
+		{
+			name: "same key with another body is rejected", status: http.StatusCreated,
+			second:     idempotentRequest{key: "key-1", body: `{"amount":2}`, userID: "alice"},
+			wantStatus: http.StatusUnprocessableEntity, wantCalls: 1,
+		},
+		{
+			name: "same key on another route is rejected", status: http.StatusCreated,
+			second:     idempotentRequest{path: "/refunds", key: "key-1", body: `{"amount":1}`, userID: "alice"},
+			wantStatus: http.StatusUnprocessableEntity, wantCalls: 1,
+		},
+		{
+			name: "keys are scoped per user", status: http.StatusCreated,
+			second:     idempotentRequest{key: "key-1", body: `{"amount":1}`, userID: "bob"},
+			wantStatus: http.StatusCreated, wantCalls: 2,
+		},
+		{
+			name: "other key executes again", status: http.StatusCreated,
+			second:     idempotentRequest{key: "key-2", body: `{"amount":1}`, userID: "alice"},
+			wantStatus: http.StatusCreated, wantCalls: 2,
+		},
+		{
+			name: "client errors are replayed", status: http.StatusBadRequest,
+			second:     first,
+			wantStatus: http.StatusBadRequest, wantCalls: 1, wantReplay: true,
+		},
+		{
+			name: "server errors are not stored", status: http.StatusInternalServerError,
+			second:     first,
+			wantStatus: http.StatusInternalServerError, wantCalls: 2,
+		},
+		{
+			name: "no-store responses are not stored", status: http.StatusOK,
+			header:     http.Header{"Cache-Control": {"private, no-store"}},
+			second:     first,
+			wantStatus: http.StatusOK, wantCalls: 2,
+		},
+		{
+			name: "requests without a key are not deduplicated", status: http.StatusCreated,
+			second:     idempotentRequest{body: `{"amount":1}`, userID: "alice"},
+			wantStatus: http.StatusCreated, wantCalls: 2,
+		},
+		{
+			name: "safe methods are not deduplicated", status: http.StatusOK,
+			second:     idempotentRequest{method: http.MethodGet, key: "key-1", userID: "alice"},
+			wantStatus: http.StatusOK, wantCalls: 2,
+		},
+		{
+			name: "overlong keys are rejected", status: http.StatusCreated,
+			second:     idempotentRequest{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{"amount":1}`, userID: "alice"},
+			wantStatus: http.StatusBadRequest, wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingHandler{status: tt.status, header: tt.header}
+			handler := NewIdempotencyMiddleware(newMemoryIdempotencyStore(), testIdempotencySecret, time.Hour, time.Minute).Handle(next)
+
+			original := first.send(handler)
+			got := tt.second.send(handler)
+
+			if got.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got.Code, tt.wantStatus)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", next.calls, tt.wantCalls)
+			}
+			replayed := got.Header().Get(IdempotentReplayedHeader) == "true"
+			if replayed != tt.wantReplay {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+			if tt.wantReplay && (got.Body.String() != original.Body.String() || got.Header().Get("Content-Type") != "application/json") {
+				t.Errorf("replayed %q with %v, want %q", got.Body, got.Header(), original.Body)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareRejectsConcurrentRetry(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	m := NewIdempotencyMiddleware(store, testIdempotencySecret, time.Hour, time.Minute)
+	req := idempotentRequest{key: "key-1", body: `{}`, userID: "alice"}
+
+	var retry *httptest.ResponseRecorder
+	handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The retry arrives while the first request is still running
+		if retry == nil {
+			retry = req.send(m.Handle(http.NotFoundHandler()))
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	req.send(handler)
+
+	if retry.Code != http.StatusConflict || retry.Header().Get("Retry-After") == "" {
+		t.Errorf("concurrent retry = %d with Retry-After %q, want 409 with Retry-After", retry.Code, retry.Header().Get("Retry-After"))
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnPanic(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	m := NewIdempotencyMiddleware(store, testIdempotencySecret, time.Hour, time.Minute)
+	req := idempotentRequest{key: "key-1", body: `{}`, userID: "alice"}
+
+	func() {
+		defer func() { _ = recover() }()
+		req.send(m.Handle(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })))
+	}()
+
+	next := &countingHandler{status: http.StatusCreated}
+	if got := req.send(m.Handle(next)); got.Code != http.StatusCreated || next.calls != 1 {
+		t.Errorf("retry after panic = %d with %d calls, want 201 with 1 call", got.Code, next.calls)
+	}
+}
+
+func TestIdempotencyFingerprintIsKeyed(t *testing.T) {
+	body := []byte(`{"email":"jane@example.com","password":"correct horse battery staple"}`)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+
+	a := NewIdempotencyMiddleware(nil, []byte("secret-a"), time.Hour, time.Minute).fingerprint(r, body)
+	b := NewIdempotencyMiddleware(nil, []byte("secret-b"), time.Hour, time.Minute).fingerprint(r, body)
+	if a == b {
+		t.Error("fingerprints under different secrets are equal")
+	}
+	if again := NewIdempotencyMiddleware(nil, []byte("secret-a"), time.Hour, time.Minute).fingerprint(r, body); again != a {
+		t.Error("fingerprints under the same secret differ")
+	}
+}
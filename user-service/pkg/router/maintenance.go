@@ -0,0 +1,69 @@
+package router
+
+import (
+	"math"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Nezent/microservice-template/user-service/pkg/response"
+)
+
+// maintenanceExemptPaths keep answering during maintenance so that probes do
+// not restart instances that are only down on purpose.
+var maintenanceExemptPaths = []string{"/health"}
+
+// MaintenanceState describes whether the service is down for maintenance.
+type MaintenanceState struct {
+	Enabled bool
+	Message string
+	// RetryAfter is sent to clients as a hint, if set.
+	RetryAfter time.Duration
+	// Allowed are the client networks, such as admin addresses, that are
+	// still served.
+	Allowed []netip.Prefix
+}
+
+// MaintenanceSource reports the current maintenance state. It is consulted
+// on every request and must answer from memory.
+type MaintenanceSource interface {
+	Maintenance() MaintenanceState
+}
+
+// MaintenanceMiddleware answers 503 Service Unavailable while maintenance is
+// on, except for health probes and allowed clients. It must run after
+// ClientIPMiddleware, or clients behind a proxy are never recognised.
+type MaintenanceMiddleware struct {
+	source MaintenanceSource
+}
+
+func NewMaintenanceMiddleware(source MaintenanceSource) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		source: source,
+	}
+}
+
+func (m *MaintenanceMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(maintenanceExemptPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		state := m.source.Maintenance()
+		if !state.Enabled || state.allows(ClientIP(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if state.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(state.RetryAfter.Seconds()))))
+		}
+		response.WriteErrorCode(w, "MAINTENANCE", state.Message, http.StatusServiceUnavailable)
+	})
+}
+
+func (s MaintenanceState) allows(ip string) bool {
+	return containsIP(s.Allowed, ip)
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+type staticMaintenance MaintenanceState
+
+func (s staticMaintenance) Maintenance() MaintenanceState { return MaintenanceState(s) }
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	state := staticMaintenance{
+		Enabled: true,
+		Message: "Under Maintenance",
+		Allowed: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")},
+	}
+	clientIP := NewClientIPMiddleware([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	handler := clientIP.Handle(NewMaintenanceMiddleware(state).Handle(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		realIP     string
+		want       int
+	}{
+		{"other client", "/api/v1/users/me", "198.51.100.9:4000", "", http.StatusServiceUnavailable},
+		{"allowed client", "/api/v1/users/me", "203.0.113.7:4000", "", http.StatusOK},
+		{"allowed client behind the proxy", "/api/v1/users/me", "10.1.2.3:4000", "203.0.113.7", http.StatusOK},
+		{"other client behind the proxy", "/api/v1/users/me", "10.1.2.3:4000", "198.51.100.9", http.StatusServiceUnavailable},
+		{"client claiming an allowed address", "/api/v1/users/me", "198.51.100.9:4000", "203.0.113.7", http.StatusServiceUnavailable},
+		{"health probe", "/health", "198.51.100.9:4000", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
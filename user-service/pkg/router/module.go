@@ -6,5 +6,12 @@ var Module = fx.Module(
 	"router",
 	fx.Provide(
 		NewRouter,
+		NewAuthMiddleware,
+		// The admin API authenticates with admin tokens.
+		fx.Annotate(
+			NewAuthMiddleware,
+			fx.ParamTags(`name:"admin"`),
+			fx.ResultTags(`name:"admin"`),
+		),
 	),
 )
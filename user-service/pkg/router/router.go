@@ -3,16 +3,26 @@ package router
 import (
 	"net/http"
 	"time"
+	"unicode"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-func NewRouter() *chi.Mux {
+func NewRouter(clientIP *ClientIPMiddleware, maintenance *MaintenanceMiddleware) *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Use(middleware.Recoverer)
 
+	// Tag each request with an ID so audit entries can be traced back to it
+	router.Use(RequestID)
+
+	// Resolve the client address for throttling, audit and maintenance
+	router.Use(clientIP.Handle)
+
+	// Turn requests away while the service is down for maintenance
+	router.Use(maintenance.Handle)
+
 	// Request size limiting (prevent large payloads)
 	router.Use(middleware.RequestSize(1024 * 1024)) // 1MB limit
 
@@ -38,3 +48,42 @@ func NewRouter() *chi.Mux {
 	})
 	return router
 }
+
+// NoStore marks responses as not cacheable. Use it on routes whose responses
+// carry credentials such as tokens or secrets; IdempotencyMiddleware never
+// stores these responses either.
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxRequestIDLength is the size of audit_log.request_id.
+const maxRequestIDLength = 100
+
+// RequestID tags each request with an ID. The X-Request-Id set by the
+// gateway is kept if it is printable ASCII of at most maxRequestIDLength
+// bytes; otherwise a new ID is generated, so clients cannot put arbitrary
+// data into logs and audit entries.
+func RequestID(next http.Handler) http.Handler {
+	tagged := middleware.RequestID(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(middleware.RequestIDHeader); id != "" && !validRequestID(id) {
+			r.Header.Del(middleware.RequestIDHeader)
+		}
+		tagged.ServeHTTP(w, r)
+	})
+}
+
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c > unicode.MaxASCII || !unicode.IsPrint(c) {
+			return false
+		}
+	}
+	return true
+}
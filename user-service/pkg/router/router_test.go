@@ -0,0 +1,45 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"gateway id", "0f8fad5bd9cb469fa16570867728950e", true},
+		{"longest allowed", strings.Repeat("a", maxRequestIDLength), true},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"control characters", "abc\x1b[31m", false},
+		{"non-ascii", "abcé", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+
+			var got string
+			RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = middleware.GetReqID(r.Context())
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if tt.keep && got != tt.header {
+				t.Errorf("request id = %q, want the header's %q", got, tt.header)
+			}
+			if !tt.keep && (got == "" || got == tt.header || len(got) > maxRequestIDLength) {
+				t.Errorf("request id = %q, want a generated id", got)
+			}
+		})
+	}
+}